@@ -372,7 +372,7 @@ func (t *AuthorizationTransport) RoundTrip(req *http.Request) (*http.Response, e
 	}
 
 	// 增加 Authorization header
-	authTime := NewAuthTime(defaultAuthExpire)
+	authTime := requestAuthTime(req)
 	AddAuthorizationHeader(ak, sk, token, req, authTime)
 
 	resp, err := t.transport(req).RoundTrip(req)
@@ -493,7 +493,7 @@ func (t *CVMCredentialTransport) RoundTrip(req *http.Request) (*http.Response, e
 	}
 	req = cloneRequest(req)
 	// 增加 Authorization header
-	authTime := NewAuthTime(defaultAuthExpire)
+	authTime := requestAuthTime(req)
 	AddAuthorizationHeader(ak, sk, token, req, authTime)
 
 	resp, err := t.transport().RoundTrip(req)
@@ -521,7 +521,7 @@ func (t *CredentialTransport) RoundTrip(req *http.Request) (*http.Response, erro
 
 	req = cloneRequest(req)
 	// 增加 Authorization header
-	authTime := NewAuthTime(defaultAuthExpire)
+	authTime := requestAuthTime(req)
 	AddAuthorizationHeader(ak, sk, token, req, authTime)
 
 	resp, err := t.transport().RoundTrip(req)
@@ -689,7 +689,7 @@ func (t *StsCredentialTransport) RoundTrip(req *http.Request) (*http.Response, e
 	}
 	req = cloneRequest(req)
 	// 增加 Authorization header
-	authTime := NewAuthTime(defaultAuthExpire)
+	authTime := requestAuthTime(req)
 	AddAuthorizationHeader(ak, sk, token, req, authTime)
 
 	resp, err := t.transport().RoundTrip(req)
@@ -1,11 +1,11 @@
 package cos
 
 import (
-	"bytes"
 	"context"
 	"encoding/xml"
 	"errors"
 	"net/http"
+	"strings"
 )
 
 // BucketService 相关 API
@@ -241,7 +241,11 @@ func (s *BucketService) GetMeta(ctx context.Context, bucket ...string) (*BucketG
 		return nil, nil, errors.New("BucketURL is empty")
 	}
 	var customDomain bool
-	if !hostPrefix.MatchString(s.client.BaseURL.BucketURL.String()) {
+	if prefix := basePath(s.client.BaseURL.BucketURL); prefix != "" {
+		// path-style 地址从 path 中获取存储桶名称
+		customDomain = true
+		bucket = []string{strings.TrimPrefix(prefix, "/")}
+	} else if !hostPrefix.MatchString(s.client.BaseURL.BucketURL.String()) {
 		customDomain = true
 		if len(bucket) == 0 || !bucketChecker.MatchString(bucket[0]) {
 			return nil, nil, errors.New("you must provide bucket-appid param in using custom domain")
@@ -261,13 +265,12 @@ func (s *BucketService) GetMeta(ctx context.Context, bucket ...string) (*BucketG
 	} else {
 		res.BucketName, res.Location = bucket[0], resp.Header.Get("X-Cos-Bucket-Region")
 	}
-	w := bytes.NewBuffer(nil)
-	bucketURLTemplate.Execute(w, struct {
-		Schema     string
-		BucketName string
-		Region     string
-	}{"https", res.BucketName, res.Location})
-	res.BucketUrl = w.String()
+	if ep, err := (DefaultEndpointResolver{}).ResolveEndpoint(EndpointBucket, &EndpointOptions{
+		Bucket: res.BucketName,
+		Region: res.Location,
+	}); err == nil {
+		res.BucketUrl = ep.URL.String()
+	}
 
 	if resp.Header.Get("X-Cos-Bucket-Az-Type") == "MAZ" {
 		res.MAZ = true
@@ -0,0 +1,130 @@
+package cos
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RestorePrefixToTime 对单个对象采取的操作
+const (
+	// 对象在该时间点之后没有变化
+	PointInTimeUnchanged = "unchanged"
+	// 复制该时间点的版本为当前版本
+	PointInTimeRestore = "restore"
+	// 对象在该时间点不存在，添加删除标记
+	PointInTimeDelete = "delete"
+)
+
+// RestorePrefixToTimeOptions 是 RestorePrefixToTime 的选项
+type RestorePrefixToTimeOptions struct {
+	// 只计算每个对象需要的操作，不执行
+	DryRun bool
+	// 并发数，默认为 1
+	ThreadPoolSize int
+	// 复制历史版本的选项
+	CopyOptions *MultiCopyOptions
+}
+
+// PointInTimeResult 是 RestorePrefixToTime 中单个对象的结果
+type PointInTimeResult struct {
+	Key    string
+	Action string
+	// 该时间点的版本，对象在该时间点不存在或者已删除时为 nil
+	Target *ObjectVersion
+	// 操作前的当前版本
+	Current *ObjectVersion
+	Err     error
+}
+
+// RestorePrefixToTime 将多版本存储桶中 prefix 下的对象恢复到时间点 t 的状态：
+// 对于每个对象找到 t 时刻的当前版本，与现在的当前版本不同时复制该版本为当前版本；
+// t 时刻不存在或者已删除的对象添加删除标记；未变化的对象不做处理。
+// 所有操作都不会删除任何已有版本。
+//
+// 结果按对象名排序，单个对象的失败记录在对应的 PointInTimeResult.Err 中，
+// 返回的 error 仅表示列举失败或者 ctx 结束。
+func (s *BucketService) RestorePrefixToTime(ctx context.Context, prefix string, t time.Time, opt *RestorePrefixToTimeOptions) ([]PointInTimeResult, error) {
+	if t.IsZero() {
+		return nil, errors.New("point in time is zero")
+	}
+	if opt == nil {
+		opt = &RestorePrefixToTimeOptions{}
+	}
+	poolSize := opt.ThreadPoolSize
+	if poolSize <= 0 {
+		poolSize = 1
+	}
+	jobs := make(chan PointInTimeResult, poolSize)
+	var mu sync.Mutex
+	var results []PointInTimeResult
+	var wg sync.WaitGroup
+	for w := 0; w < poolSize; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for res := range jobs {
+				switch res.Action {
+				case PointInTimeRestore:
+					_, _, res.Err = s.client.Object.RestoreVersion(ctx, res.Key, res.Target.VersionId, opt.CopyOptions)
+				case PointInTimeDelete:
+					_, res.Err = s.client.Object.Delete(ctx, res.Key)
+				}
+				mu.Lock()
+				results = append(results, res)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	err := s.client.walkObjectVersions(ctx, prefix, func(h *ObjectVersionHistory) error {
+		res := pointInTimeAction(h, t)
+		if opt.DryRun || res.Action == PointInTimeUnchanged {
+			mu.Lock()
+			results = append(results, res)
+			mu.Unlock()
+			return nil
+		}
+		select {
+		case jobs <- res:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	close(jobs)
+	wg.Wait()
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Key < results[j].Key
+	})
+	return results, err
+}
+
+// pointInTimeAction 计算对象恢复到时间点 t 所需的操作
+func pointInTimeAction(h *ObjectVersionHistory, t time.Time) PointInTimeResult {
+	res := PointInTimeResult{Key: h.Key, Current: h.Latest()}
+	// Versions 从新到旧排列，第一个不晚于 t 的即为 t 时刻的当前版本
+	for i := range h.Versions {
+		if !h.Versions[i].LastModified.After(t) {
+			res.Target = &h.Versions[i]
+			break
+		}
+	}
+	if res.Target != nil && res.Target.IsDeleteMarker {
+		res.Target = nil
+	}
+	currentExists := res.Current != nil && !res.Current.IsDeleteMarker
+	switch {
+	case res.Target == nil && !currentExists:
+		res.Action = PointInTimeUnchanged
+	case res.Target == nil:
+		res.Action = PointInTimeDelete
+	case currentExists && res.Current.VersionId == res.Target.VersionId:
+		res.Action = PointInTimeUnchanged
+	default:
+		res.Action = PointInTimeRestore
+	}
+	return res
+}
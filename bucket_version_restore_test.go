@@ -0,0 +1,93 @@
+package cos
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBucketService_RestorePrefixToTime(t *testing.T) {
+	setup()
+	defer teardown()
+	testVersionsServer(t, nil)
+
+	var mu sync.Mutex
+	var ops []string
+	record := func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ops = append(ops, r.Method+" "+r.URL.Path+" "+r.URL.Query().Get("versionId"))
+		mu.Unlock()
+		switch r.Method {
+		case http.MethodHead:
+			w.Header().Set("Content-Length", "1")
+		case http.MethodPut:
+			fmt.Fprint(w, `<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`)
+		}
+	}
+	mux.HandleFunc("/conf/a.json", record)
+	mux.HandleFunc("/conf/a.jsonx", record)
+	mux.HandleFunc("/conf/b.json", record)
+
+	actions := func(results []PointInTimeResult) map[string]string {
+		m := map[string]string{}
+		for _, r := range results {
+			if r.Err != nil {
+				t.Errorf("RestorePrefixToTime %v returned error: %v", r.Key, r.Err)
+			}
+			m[r.Key] = r.Action
+			if r.Target != nil {
+				m[r.Key] += " " + r.Target.VersionId
+			}
+		}
+		return m
+	}
+
+	pit := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	results, err := client.Bucket.RestorePrefixToTime(context.Background(), "conf/", pit, &RestorePrefixToTimeOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Bucket.RestorePrefixToTime returned error: %v", err)
+	}
+	want := map[string]string{
+		"conf/a.json":  "restore v1",
+		"conf/a.jsonx": "unchanged x1",
+		"conf/b.json":  "restore b1",
+	}
+	if got := actions(results); !reflect.DeepEqual(got, want) || len(ops) != 0 {
+		t.Errorf("Bucket.RestorePrefixToTime dry run returned %v, want %v, requests: %v", got, want, ops)
+	}
+
+	pit = time.Date(2023, 1, 2, 12, 0, 0, 0, time.UTC)
+	results, err = client.Bucket.RestorePrefixToTime(context.Background(), "conf/", pit, &RestorePrefixToTimeOptions{ThreadPoolSize: 3})
+	if err != nil {
+		t.Fatalf("Bucket.RestorePrefixToTime returned error: %v", err)
+	}
+	want = map[string]string{
+		"conf/a.json":  "restore v2",
+		"conf/a.jsonx": "unchanged x1",
+		"conf/b.json":  "unchanged b2",
+	}
+	wantOps := []string{"HEAD /conf/a.json v2", "PUT /conf/a.json "}
+	if got := actions(results); !reflect.DeepEqual(got, want) || !reflect.DeepEqual(ops, wantOps) {
+		t.Errorf("Bucket.RestorePrefixToTime returned %v, want %v, requests: %v", got, want, ops)
+	}
+
+	ops = nil
+	pit = time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC)
+	results, err = client.Bucket.RestorePrefixToTime(context.Background(), "conf/", pit, nil)
+	if err != nil {
+		t.Fatalf("Bucket.RestorePrefixToTime returned error: %v", err)
+	}
+	want = map[string]string{
+		"conf/a.json":  "unchanged",
+		"conf/a.jsonx": "delete",
+		"conf/b.json":  "delete",
+	}
+	wantOps = []string{"DELETE /conf/a.jsonx ", "DELETE /conf/b.json "}
+	if got := actions(results); !reflect.DeepEqual(got, want) || !reflect.DeepEqual(ops, wantOps) {
+		t.Errorf("Bucket.RestorePrefixToTime returned %v, want %v, requests: %v", got, want, ops)
+	}
+}
@@ -1176,6 +1176,7 @@ func (s *CIService) Put(ctx context.Context, name string, r io.Reader, uopt *Obj
 		return nil, nil, err
 	}
 	opt := CloneObjectPutOptions(uopt)
+	s.client.applyUploadHeaderPolicy(name, opt.ObjectPutHeaderOptions)
 	totalBytes, err := GetReaderLen(r)
 	if err != nil && opt != nil && opt.Listener != nil {
 		if opt.ContentLength == 0 {
@@ -0,0 +1,416 @@
+package cos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Option 配置 New 创建的 Client，参数不合法时返回错误
+type Option func(*clientOptions) error
+
+type clientOptions struct {
+	baseURL        *BaseURL
+	resolver       EndpointResolver
+	endpoint       EndpointOptions
+	hasBucket      bool
+	httpClient     *http.Client
+	credential     *Credential
+	retry          *RetryOptions
+	timeouts       *Timeouts
+	uaSuffix       string
+	endpointDomain string
+	crc            *bool
+	partSize       int64
+	poolSize       int
+	hedge          *HedgePolicy
+	rateLimit      *RateLimit
+}
+
+// Timeouts 是 WithTimeouts 使用的超时配置，为 0 时不限制
+type Timeouts struct {
+	// 建立连接的超时时间
+	Connect time.Duration
+	// 发送请求后等待响应头部的超时时间
+	ResponseHeader time.Duration
+	// 整个请求的超时时间，包括读取响应 body，下载大文件时需要设置足够长
+	Request time.Duration
+}
+
+// WithBucket 设置存储桶和地域，Bucket、Service、CI 等服务的地址由 DefaultEndpointResolver 生成，
+// Service API 使用该地域的域名
+//
+//	name: 存储桶名称，格式为 {name}-{appid}
+//	region: 地域代码，例如 ap-guangzhou
+func WithBucket(name, region string) Option {
+	return func(o *clientOptions) error {
+		if !bucketChecker.MatchString(name) {
+			return fmt.Errorf("bucketName[%v] is invalid, must be {name}-{appid}", name)
+		}
+		if region == "" || !regionChecker.MatchString(region) {
+			return fmt.Errorf("region[%v] is invalid", region)
+		}
+		o.endpoint.Bucket = name
+		o.endpoint.Region = region
+		o.hasBucket = true
+		return nil
+	}
+}
+
+// WithBaseURL 直接指定各服务的地址，与 WithBucket 同时使用时以 WithBucket 生成的地址为准
+func WithBaseURL(u *BaseURL) Option {
+	return func(o *clientOptions) error {
+		if u == nil {
+			return errors.New("BaseURL is nil")
+		}
+		o.baseURL = u
+		return nil
+	}
+}
+
+// WithEndpointResolver 使用自定义的解析器生成服务地址，参见 Client.SetEndpointResolver
+func WithEndpointResolver(r EndpointResolver) Option {
+	return func(o *clientOptions) error {
+		if r == nil {
+			return errors.New("EndpointResolver is nil")
+		}
+		o.resolver = r
+		return nil
+	}
+}
+
+// WithAccelerate 使用全球加速域名访问存储桶
+func WithAccelerate() Option {
+	return func(o *clientOptions) error {
+		o.endpoint.Accelerate = true
+		return nil
+	}
+}
+
+// WithInternalEndpoint 使用内网域名访问存储桶
+func WithInternalEndpoint() Option {
+	return func(o *clientOptions) error {
+		o.endpoint.Internal = true
+		return nil
+	}
+}
+
+// WithEndpoint 使用指定的服务域名访问存储桶，需要同时使用 WithBucket。
+// endpoint 不包含存储桶名称，可以带 scheme，例如 cos.ap-guangzhou.myqcloud.com 或者 http://127.0.0.1:9000，
+// 默认使用 {bucket}.{endpoint}，同时使用 WithPathStyle 时使用 {endpoint}/{bucket}
+func WithEndpoint(endpoint string) Option {
+	return func(o *clientOptions) error {
+		if endpoint == "" {
+			return errors.New("endpoint is empty")
+		}
+		o.endpointDomain = strings.TrimRight(endpoint, "/")
+		return nil
+	}
+}
+
+// WithPathStyle 使用 path-style 访问存储桶，适用于本地的 COS/S3 兼容服务
+func WithPathStyle() Option {
+	return func(o *clientOptions) error {
+		o.endpoint.PathStyle = true
+		return nil
+	}
+}
+
+// WithScheme 设置访问使用的协议，http 或者 https，默认为 https
+func WithScheme(scheme string) Option {
+	return func(o *clientOptions) error {
+		if scheme != "http" && scheme != "https" {
+			return fmt.Errorf("scheme[%v] is invalid", scheme)
+		}
+		o.endpoint.Scheme = scheme
+		return nil
+	}
+}
+
+// WithCredentials 使用永久密钥或者临时密钥签名请求
+func WithCredentials(secretID, secretKey string, sessionToken ...string) Option {
+	return func(o *clientOptions) error {
+		if secretID == "" || secretKey == "" {
+			return errors.New("SecretID and SecretKey must not be empty")
+		}
+		o.credential = &Credential{SecretID: secretID, SecretKey: secretKey}
+		if len(sessionToken) > 0 {
+			o.credential.SessionToken = sessionToken[0]
+		}
+		return nil
+	}
+}
+
+// WithRetryPolicy 设置失败重试策略，Count 为总的请求次数
+func WithRetryPolicy(opt RetryOptions) Option {
+	return func(o *clientOptions) error {
+		if opt.Count < 0 || opt.Interval < 0 {
+			return fmt.Errorf("retry count[%v] and interval[%v] must not be negative", opt.Count, opt.Interval)
+		}
+		o.retry = &opt
+		return nil
+	}
+}
+
+// WithHTTPClient 使用指定的 http.Client 发送请求，New 会复制一份，不会修改 c。
+// 同时使用 WithCredentials 时，签名在 c.Transport 之前进行
+func WithHTTPClient(c *http.Client) Option {
+	return func(o *clientOptions) error {
+		if c == nil {
+			return errors.New("http.Client is nil")
+		}
+		o.httpClient = c
+		return nil
+	}
+}
+
+// WithTimeouts 设置连接、响应头部和整个请求的超时时间。
+// 设置 Connect 或者 ResponseHeader 时，WithHTTPClient 的 Transport 必须为 nil 或者 *http.Transport
+func WithTimeouts(t Timeouts) Option {
+	return func(o *clientOptions) error {
+		if t.Connect < 0 || t.ResponseHeader < 0 || t.Request < 0 {
+			return fmt.Errorf("timeouts %+v must not be negative", t)
+		}
+		o.timeouts = &t
+		return nil
+	}
+}
+
+// WithCRC 开启或者关闭上传下载的 CRC64 校验，默认开启
+func WithCRC(enabled bool) Option {
+	return func(o *clientOptions) error {
+		o.crc = &enabled
+		return nil
+	}
+}
+
+// WithTransferDefaults 设置 Upload/Download 未指定时使用的分块大小（MB）和并发数
+func WithTransferDefaults(partSize int64, threadPoolSize int) Option {
+	return func(o *clientOptions) error {
+		if partSize < 0 || threadPoolSize < 0 {
+			return fmt.Errorf("part size[%v] and thread pool size[%v] must not be negative", partSize, threadPoolSize)
+		}
+		o.partSize, o.poolSize = partSize, threadPoolSize
+		return nil
+	}
+}
+
+// WithHedgePolicy 开启 Bucket/Object 读请求的对冲，参见 HedgePolicy
+func WithHedgePolicy(p *HedgePolicy) Option {
+	return func(o *clientOptions) error {
+		if p == nil || p.Delay < 0 || p.Percentile < 0 || p.Percentile > 1 {
+			return errors.New("hedge policy is invalid")
+		}
+		if p.Delay == 0 && p.Percentile == 0 {
+			return errors.New("hedge policy requires Delay or Percentile")
+		}
+		o.hedge = p
+		return nil
+	}
+}
+
+// WithRateLimit 设置 Client 级别的带宽和请求数限制，参见 RateLimit。
+// 与 WithBandwidthLimit 同时使用时按字段合并，l 中为 nil 的字段保留之前的设置
+func WithRateLimit(l *RateLimit) Option {
+	return func(o *clientOptions) error {
+		if l == nil {
+			return errors.New("rate limit is nil")
+		}
+		o.rateLimit = mergeRateLimit(o.rateLimit, l)
+		return nil
+	}
+}
+
+// WithBandwidthLimit 限制上传和下载的总带宽，单位 Byte/s，为 0 时保留之前的设置，不设置时不限制
+func WithBandwidthLimit(upload, download int64) Option {
+	return func(o *clientOptions) error {
+		if upload < 0 || download < 0 {
+			return fmt.Errorf("bandwidth limit[%v, %v] must not be negative", upload, download)
+		}
+		o.rateLimit = mergeRateLimit(o.rateLimit, NewBandwidthLimit(upload, download))
+		return nil
+	}
+}
+
+// mergeRateLimit 返回 base 和 l 合并后的副本，l 中不为 nil 的字段覆盖 base
+func mergeRateLimit(base, l *RateLimit) *RateLimit {
+	merged := &RateLimit{}
+	if base != nil {
+		*merged = *base
+	}
+	if l.Upload != nil {
+		merged.Upload = l.Upload
+	}
+	if l.Download != nil {
+		merged.Download = l.Download
+	}
+	if l.Requests != nil {
+		merged.Requests = l.Requests
+	}
+	return merged
+}
+
+// WithUserAgentSuffix 在默认的 User-Agent 之后追加 suffix，用于区分调用方
+func WithUserAgentSuffix(suffix string) Option {
+	return func(o *clientOptions) error {
+		o.uaSuffix = suffix
+		return nil
+	}
+}
+
+// New 使用 Option 创建 Client，配置不合法时返回错误而不是在发送请求时返回 invalidBucketErr。
+// Client 创建后不应再修改 Conf，多个 goroutine 共享 Client 时应通过 Option 完成配置
+//
+//	client, err := cos.New(
+//		cos.WithBucket("examplebucket-1250000000", "ap-guangzhou"),
+//		cos.WithCredentials(os.Getenv("SECRETID"), os.Getenv("SECRETKEY")),
+//	)
+func New(opts ...Option) (*Client, error) {
+	o := &clientOptions{}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+	if o.endpoint.Accelerate && o.endpoint.Internal {
+		return nil, errors.New("WithAccelerate and WithInternalEndpoint can not be used together")
+	}
+	if (o.endpoint.Accelerate || o.endpoint.Internal || o.endpoint.PathStyle || o.endpointDomain != "") && !o.hasBucket {
+		return nil, errors.New("WithAccelerate, WithInternalEndpoint, WithPathStyle and WithEndpoint require WithBucket")
+	}
+	if o.endpointDomain != "" {
+		o.endpoint.CustomDomain = o.customDomain()
+	} else if o.endpoint.PathStyle && o.endpoint.Scheme == "" {
+		o.endpoint.Scheme = "https"
+	}
+
+	httpClient, err := o.newHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	c := NewClient(o.baseURL, httpClient)
+	if o.hasBucket || o.resolver != nil {
+		resolver := o.resolver
+		if resolver == nil {
+			resolver = DefaultEndpointResolver{}
+		}
+		if err = c.SetEndpointResolver(resolver, &o.endpoint); err != nil {
+			return nil, err
+		}
+	}
+	if err = c.checkBaseURL(); err != nil {
+		return nil, err
+	}
+	if o.retry != nil {
+		c.Conf.RetryOpt = *o.retry
+	}
+	if o.crc != nil {
+		c.Conf.EnableCRC = *o.crc
+	}
+	c.Conf.PartSize, c.Conf.ThreadPoolSize = o.partSize, o.poolSize
+	c.Conf.Hedge = o.hedge
+	c.Conf.RateLimit = o.rateLimit
+	if o.uaSuffix != "" {
+		c.UserAgent = c.UserAgent + " " + o.uaSuffix
+	}
+	return c, nil
+}
+
+// customDomain 根据 WithEndpoint 生成存储桶的访问域名
+func (o *clientOptions) customDomain() string {
+	scheme, host := o.endpoint.Scheme, o.endpointDomain
+	if i := strings.Index(host, "://"); i >= 0 {
+		scheme, host = host[:i], host[i+3:]
+	}
+	if scheme == "" {
+		scheme = "https"
+	}
+	if !o.endpoint.PathStyle {
+		host = o.endpoint.Bucket + "." + host
+	}
+	return scheme + "://" + host
+}
+
+func (o *clientOptions) newHTTPClient() (*http.Client, error) {
+	c := &http.Client{}
+	if o.httpClient != nil {
+		*c = *o.httpClient
+	}
+	if t := o.timeouts; t != nil {
+		if t.Request > 0 {
+			c.Timeout = t.Request
+		}
+		if t.Connect > 0 || t.ResponseHeader > 0 {
+			var tr *http.Transport
+			switch v := c.Transport.(type) {
+			case nil:
+				// 与 AuthorizationTransport 的默认行为一致，内网域名使用 DNS 打散
+				tr = http.DefaultTransport.(*http.Transport).Clone()
+				if o.endpoint.Internal {
+					tr = DNSScatterTransport.Clone()
+				}
+			case *http.Transport:
+				tr = v.Clone()
+			default:
+				return nil, fmt.Errorf("WithTimeouts requires *http.Transport, got %T", c.Transport)
+			}
+			if t.Connect > 0 {
+				dial := tr.DialContext
+				if dial == nil {
+					dial = (&net.Dialer{KeepAlive: 30 * time.Second}).DialContext
+				}
+				tr.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+					ctx, cancel := context.WithTimeout(ctx, t.Connect)
+					defer cancel()
+					return dial(ctx, network, addr)
+				}
+				tr.TLSHandshakeTimeout = t.Connect
+			}
+			if t.ResponseHeader > 0 {
+				tr.ResponseHeaderTimeout = t.ResponseHeader
+			}
+			c.Transport = tr
+		}
+	}
+	if cred := o.credential; cred != nil {
+		c.Transport = &AuthorizationTransport{
+			SecretID:     cred.SecretID,
+			SecretKey:    cred.SecretKey,
+			SessionToken: cred.SessionToken,
+			Transport:    c.Transport,
+		}
+	}
+	return c, nil
+}
+
+// checkBaseURL 按发送请求时的规则校验地址，返回第一个不合法的地址
+func (c *Client) checkBaseURL() error {
+	urls := []struct {
+		name string
+		u    *url.URL
+	}{
+		{"BucketURL", c.BaseURL.BucketURL},
+		{"ServiceURL", c.BaseURL.ServiceURL},
+		{"BatchURL", c.BaseURL.BatchURL},
+	}
+	for _, v := range urls {
+		if v.u == nil || c.resolvedEndpoint(v.u) != nil {
+			continue
+		}
+		if !c.BaseURL.innerCheck(v.u, bucketDomainChecker) || !checkURL(v.u) {
+			return fmt.Errorf("%v[%v] is invalid: %w", v.name, v.u, invalidBucketErr)
+		}
+	}
+	if c.invalidURL {
+		return invalidBucketErr
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package cos
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	c, err := New(
+		WithBucket("examplebucket-1250000000", "ap-guangzhou"),
+		WithAccelerate(),
+		WithRetryPolicy(RetryOptions{Count: 5, Interval: time.Second}),
+		WithUserAgentSuffix("app/1.0"),
+	)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if got := c.BaseURL.BucketURL.String(); got != "https://examplebucket-1250000000.cos.accelerate.myqcloud.com" {
+		t.Errorf("BucketURL: %v", got)
+	}
+	if got := c.BaseURL.CIURL.String(); got != "https://examplebucket-1250000000.ci.ap-guangzhou.myqcloud.com" {
+		t.Errorf("CIURL: %v", got)
+	}
+	if c.Conf.RetryOpt.Count != 5 || c.Conf.RetryOpt.Interval != time.Second {
+		t.Errorf("RetryOpt: %+v", c.Conf.RetryOpt)
+	}
+	if c.UserAgent != UserAgent+" app/1.0" {
+		t.Errorf("UserAgent: %v", c.UserAgent)
+	}
+
+	c, err = New(WithBucket("examplebucket-1250000000", "ap-guangzhou"), WithInternalEndpoint())
+	if err != nil || c.BaseURL.BucketURL.Host != "examplebucket-1250000000.cos-internal.ap-guangzhou.tencentcos.cn" {
+		t.Errorf("New with internal endpoint returned %v, error: %v", c, err)
+	}
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	bad, _ := url.Parse("https://examplebucket.cos.ap-guangzhou.myqcloud.com")
+	cases := map[string][]Option{
+		"bucket":      {WithBucket("examplebucket", "ap-guangzhou")},
+		"region":      {WithBucket("examplebucket-1250000000", "ap_guangzhou")},
+		"credentials": {WithCredentials("ak", "")},
+		"retry":       {WithRetryPolicy(RetryOptions{Count: -1})},
+		"timeouts":    {WithTimeouts(Timeouts{Connect: -1})},
+		"conflict":    {WithBucket("examplebucket-1250000000", "ap-guangzhou"), WithAccelerate(), WithInternalEndpoint()},
+		"accelerate":  {WithAccelerate()},
+		"baseurl":     {WithBaseURL(&BaseURL{BucketURL: bad})},
+		"transport":   {WithHTTPClient(&http.Client{Transport: &AuthorizationTransport{}}), WithTimeouts(Timeouts{Connect: time.Second})},
+	}
+	for name, opts := range cases {
+		if c, err := New(opts...); err == nil {
+			t.Errorf("%v: New returned %v, expect error", name, c)
+		}
+	}
+	if _, err := New(WithBaseURL(&BaseURL{BucketURL: bad})); !errors.Is(err, invalidBucketErr) || !strings.Contains(err.Error(), "BucketURL") {
+		t.Errorf("New with invalid BucketURL returned error: %v", err)
+	}
+}
+
+func TestNew_HTTPClient(t *testing.T) {
+	var auth, ua string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		ua = r.Header.Get("User-Agent")
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+
+	hc := &http.Client{Transport: &http.Transport{}}
+	c, err := New(
+		WithBaseURL(&BaseURL{BucketURL: u}),
+		WithHTTPClient(hc),
+		WithCredentials("ak", "sk", "token"),
+		WithTimeouts(Timeouts{Connect: time.Second, ResponseHeader: 50 * time.Millisecond}),
+		WithUserAgentSuffix("app/1.0"),
+	)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if hc.CheckRedirect != nil || hc.Transport.(*http.Transport).ResponseHeaderTimeout != 0 {
+		t.Errorf("New modified the http.Client")
+	}
+	if _, err = c.Object.Head(context.Background(), "a", nil); err != nil {
+		t.Fatalf("Object.Head returned error: %v", err)
+	}
+	if !strings.Contains(auth, "q-ak=ak") || !strings.HasSuffix(ua, " app/1.0") {
+		t.Errorf("Authorization: %v, User-Agent: %v", auth, ua)
+	}
+	c.Conf.RetryOpt.Count = 1
+	if _, err = c.Object.Head(context.Background(), "slow", nil); err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Errorf("Object.Head with response header timeout returned error: %v", err)
+	}
+}
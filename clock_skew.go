@@ -0,0 +1,128 @@
+package cos
+
+import (
+	"context"
+	"hash"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// 本地时钟与服务端 Date 相差超过该值时才校正，Date 头部的精度为秒
+const clockSkewThreshold = 5 * time.Second
+
+type clockOffsetKey struct{}
+
+// ClockOffset 返回签名使用的时钟偏移，签名时间为本地时间加上该偏移
+func (c *Client) ClockOffset() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.clockOffset))
+}
+
+// SetClockOffset 设置签名使用的时钟偏移。请求因 RequestTimeTooSkewed 或 AccessDenied 失败时，
+// SDK 会根据服务端的 Date 头部自动设置
+func (c *Client) SetClockOffset(d time.Duration) {
+	atomic.StoreInt64(&c.clockOffset, int64(d))
+}
+
+// withClockOffset 将当前的时钟偏移传递给签名的 Transport
+func (c *Client) withClockOffset(ctx context.Context) context.Context {
+	return context.WithValue(ctx, clockOffsetKey{}, c.ClockOffset())
+}
+
+// newAuthTime 生成按时钟偏移校正后的 AuthTime
+func (c *Client) newAuthTime(expire time.Duration) *AuthTime {
+	return newAuthTimeWithOffset(expire, c.ClockOffset())
+}
+
+func newAuthTimeWithOffset(expire, offset time.Duration) *AuthTime {
+	authTime := NewAuthTime(expire)
+	if offset != 0 {
+		authTime.SignStartTime = authTime.SignStartTime.Add(offset)
+		authTime.SignEndTime = authTime.SignEndTime.Add(offset)
+		authTime.KeyStartTime = authTime.KeyStartTime.Add(offset)
+		authTime.KeyEndTime = authTime.KeyEndTime.Add(offset)
+	}
+	return authTime
+}
+
+// requestAuthTime 生成签名 req 使用的 AuthTime，使用 Client 通过 context 传递的时钟偏移
+func requestAuthTime(req *http.Request) *AuthTime {
+	offset, _ := req.Context().Value(clockOffsetKey{}).(time.Duration)
+	return newAuthTimeWithOffset(defaultAuthExpire, offset)
+}
+
+// correctClockSkew 在签名时间错误时根据服务端的 Date 头部校正时钟偏移，返回是否发生了校正
+func (c *Client) correctClockSkew(resp *Response, err error) bool {
+	if resp == nil || !isClockSkewError(err) {
+		return false
+	}
+	serverTime, perr := http.ParseTime(resp.Header.Get("Date"))
+	if perr != nil {
+		return false
+	}
+	offset := serverTime.Sub(time.Now())
+	diff := offset - c.ClockOffset()
+	if diff < clockSkewThreshold && diff > -clockSkewThreshold {
+		return false
+	}
+	c.SetClockOffset(offset.Truncate(time.Second))
+	return true
+}
+
+// bodyRewinder 记录 body 的当前位置，返回的函数将 body 恢复到该位置以便重新发送，无法恢复时返回 false。
+// 非 io.Reader 的 body 每次发送时重新序列化，总是可以重新发送
+func bodyRewinder(body interface{}) func() bool {
+	r, ok := body.(io.Reader)
+	if !ok || r == http.NoBody {
+		return func() bool { return true }
+	}
+	tee, _ := r.(*teeReader)
+	if tee != nil {
+		r = tee.reader
+		if _, ok := tee.writer.(hash.Hash); tee.writer != nil && !ok {
+			return func() bool { return false }
+		}
+	}
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		return func() bool { return false }
+	}
+	position, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return func() bool { return false }
+	}
+	return func() bool {
+		if _, err := seeker.Seek(position, io.SeekStart); err != nil {
+			return false
+		}
+		if tee != nil {
+			tee.consumedBytes = 0
+			if h, ok := tee.writer.(hash.Hash); ok {
+				h.Reset()
+			}
+		}
+		return true
+	}
+}
+
+// signedWithStaleClock 判断失败的请求是否使用了校正前的时钟签名，重新签名后可以重试
+func (c *Client) signedWithStaleClock(resp *Response, err error) bool {
+	if resp == nil || resp.Request == nil || !isClockSkewError(err) {
+		return false
+	}
+	offset, ok := resp.Request.Context().Value(clockOffsetKey{}).(time.Duration)
+	return ok && offset != c.ClockOffset()
+}
+
+// isClockSkewError 判断是否为签名时间导致的错误，HEAD 请求没有响应 body，403 时视为 AccessDenied
+func isClockSkewError(err error) bool {
+	status, code, ok := serviceError(err)
+	if !ok {
+		return false
+	}
+	if code == "" && status == http.StatusForbidden {
+		code = ErrAccessDenied
+	}
+	return code == ErrRequestTimeTooSkewed || code == ErrAccessDenied
+}
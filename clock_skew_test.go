@@ -0,0 +1,137 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newSkewedServer 模拟时钟比本地快 skew 的服务端，签名时间相差超过 15 分钟时返回 RequestTimeTooSkewed
+func newSkewedServer(t *testing.T, skew time.Duration, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		now := time.Now().Add(skew)
+		w.Header().Set("Date", now.UTC().Format(http.TimeFormat))
+		signTime := r.URL.Query().Get("q-sign-time")
+		for _, kv := range strings.Split(r.Header.Get("Authorization"), "&") {
+			if strings.HasPrefix(kv, "q-sign-time=") {
+				signTime = strings.TrimPrefix(kv, "q-sign-time=")
+			}
+		}
+		start, _ := strconv.ParseInt(strings.SplitN(signTime, ";", 2)[0], 10, 64)
+		if d := now.Sub(time.Unix(start, 0)); d > 15*time.Minute || d < -15*time.Minute {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<Error><Code>RequestTimeTooSkewed</Code><Message>The difference between the request time and the server's time is too large.</Message></Error>`)
+			return
+		}
+	}))
+}
+
+func newSkewedClient(serverURL string) *Client {
+	u, _ := url.Parse(serverURL)
+	return NewClient(&BaseURL{BucketURL: u}, &http.Client{
+		Transport: &AuthorizationTransport{SecretID: "ak", SecretKey: "sk"},
+	})
+}
+
+func TestClient_ClockSkewCorrection(t *testing.T) {
+	var requests int
+	server := newSkewedServer(t, time.Hour, &requests)
+	defer server.Close()
+	c := newSkewedClient(server.URL)
+	c.Conf.RetryOpt.Count = 1
+
+	if _, err := c.Object.Head(context.Background(), "a", nil); err != nil {
+		t.Fatalf("Object.Head returned error: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Object.Head sent %v requests, want 2", requests)
+	}
+	if d := c.ClockOffset() - time.Hour; d > 2*time.Second || d < -2*time.Second {
+		t.Errorf("ClockOffset returned %v", c.ClockOffset())
+	}
+
+	requests = 0
+	if _, err := c.Object.Head(context.Background(), "a", nil); err != nil || requests != 1 {
+		t.Errorf("Object.Head after correction sent %v requests, error: %v", requests, err)
+	}
+
+	u, err := c.Object.GetPresignedURL(context.Background(), http.MethodGet, "a", "ak", "sk", time.Hour, nil)
+	if err != nil {
+		t.Fatalf("GetPresignedURL returned error: %v", err)
+	}
+	resp, err := http.Get(u.String())
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("presigned Get returned %v, error: %v", resp, err)
+	}
+}
+
+func TestClient_ClockSkewCorrection_StreamBody(t *testing.T) {
+	var requests int
+	server := newSkewedServer(t, -time.Hour, &requests)
+	defer server.Close()
+	c := newSkewedClient(server.URL)
+	c.Conf.EnableCRC = false
+
+	// 可以 Seek 的 body 由 Put 重新读取后重试
+	if _, err := c.Object.Put(context.Background(), "a", strings.NewReader("a"), nil); err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Object.Put sent %v requests, want 2", requests)
+	}
+}
+
+func TestClient_ClockSkewCorrection_SeekableBody(t *testing.T) {
+	var requests int
+	server := newSkewedServer(t, time.Hour, &requests)
+	defer server.Close()
+	c := newSkewedClient(server.URL)
+	c.Conf.RetryOpt.Count = 1
+	c.Conf.EnableCRC = false
+
+	// Put 本身不重试，由 send 恢复 body 的位置后重新签名发送
+	body := bytes.NewReader([]byte("0123456789"))
+	body.Seek(2, io.SeekStart)
+	if _, err := c.Object.Put(context.Background(), "a", body, nil); err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Object.Put sent %v requests, want 2", requests)
+	}
+
+	c.SetClockOffset(0)
+	requests = 0
+	if _, err := c.Object.UploadPart(context.Background(), "a", "id", 1, bytes.NewReader([]byte("part")), nil); err != nil {
+		t.Fatalf("Object.UploadPart returned error: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Object.UploadPart sent %v requests, want 2", requests)
+	}
+}
+
+func TestClient_ClockSkewCorrection_NoSkew(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>Access Denied.</Message></Error>`)
+	}))
+	defer server.Close()
+	c := newSkewedClient(server.URL)
+
+	if _, err := c.Object.Head(context.Background(), "a", nil); err == nil {
+		t.Fatalf("Object.Head expect error")
+	}
+	if requests != 1 || c.ClockOffset() != 0 {
+		t.Errorf("Object.Head sent %v requests, ClockOffset: %v", requests, c.ClockOffset())
+	}
+}
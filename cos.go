@@ -14,7 +14,6 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
-	"text/template"
 	"time"
 
 	"regexp"
@@ -37,12 +36,6 @@ const (
 )
 
 var (
-	bucketURLTemplate = template.Must(
-		template.New("bucketURLFormat").Parse(
-			"{{.Schema}}://{{.BucketName}}.cos.{{.Region}}.myqcloud.com",
-		),
-	)
-
 	// {<http://>|<https://>}{bucketname-appid}.{cos|cos-internal|cos-website|ci}.{region}.{myqcloud.com/tencentcos.cn}{/}
 	hostSuffix            = regexp.MustCompile(`^.*((cos|cos-internal|cos-website|ci)\.[a-z0-9-]+|file)\.(myqcloud\.com|tencentcos\.cn).*$`)
 	hostPrefix            = regexp.MustCompile(`^(http://|https://){0,1}([a-z0-9-]+-[0-9]+\.){0,1}((cos|cos-internal|cos-website|ci)\.[a-z0-9-]+|file)\.(myqcloud\.com|tencentcos\.cn).*$`)
@@ -65,7 +58,8 @@ var (
 
 // BaseURL 访问各 API 所需的基础 URL
 type BaseURL struct {
-	// 访问 bucket, object 相关 API 的基础 URL（不包含 path 部分）: http://example.com
+	// 访问 bucket, object 相关 API 的基础 URL: http://example.com
+	// 也可以使用 path-style 地址，path 部分为存储桶名称: http://127.0.0.1:9000/examplebucket-1250000000
 	BucketURL *url.URL
 	// 访问 service API 的基础 URL（不包含 path 部分）: http://example.com
 	ServiceURL *url.URL
@@ -113,24 +107,15 @@ func NewBucketURL(bucketName, region string, secure bool) (*url.URL, error) {
 	if !secure {
 		schema = "http"
 	}
-
-	if region == "" || !regionChecker.MatchString(region) {
-		return nil, fmt.Errorf("region[%v] is invalid", region)
-	}
-	if bucketName == "" || !strings.ContainsAny(bucketName, "-") {
-		return nil, fmt.Errorf("bucketName[%v] is invalid", bucketName)
-	}
-	w := bytes.NewBuffer(nil)
-	bucketURLTemplate.Execute(w, struct {
-		Schema     string
-		BucketName string
-		Region     string
-	}{
-		schema, bucketName, region,
+	ep, err := DefaultEndpointResolver{}.ResolveEndpoint(EndpointBucket, &EndpointOptions{
+		Bucket: bucketName,
+		Region: region,
+		Scheme: schema,
 	})
-
-	u, _ := url.Parse(w.String())
-	return u, nil
+	if err != nil {
+		return nil, err
+	}
+	return ep.URL, nil
 }
 
 type RetryOptions struct {
@@ -143,10 +128,33 @@ type Config struct {
 	RequestBodyClose       bool
 	RetryOpt               RetryOptions
 	ObjectKeySimplifyCheck bool
+	// 按对象名匹配的上传头部策略，以及 Content-Type 推断开关
+	UploadHeaderPolicy *UploadHeaderPolicy
+	// Upload/Download 未指定分块大小（MB）和并发数时使用的默认值
+	PartSize       int64
+	ThreadPoolSize int
+	// Bucket/Object 读请求的对冲策略，为 nil 时不对冲
+	Hedge *HedgePolicy
+	// Client 级别的带宽和请求数限制，为 nil 时不限制
+	RateLimit *RateLimit
+}
+
+// transferDefaults 返回分块大小和并发数，未指定时使用 Config 中的默认值
+func (c *Config) transferDefaults(partSize int64, threadPoolSize int) (int64, int) {
+	if partSize <= 0 {
+		partSize = c.PartSize
+	}
+	if threadPoolSize <= 0 {
+		threadPoolSize = c.ThreadPoolSize
+	}
+	return partSize, threadPoolSize
 }
 
 // Client is a client manages communication with the COS API.
 type Client struct {
+	// 签名使用的时钟偏移，单位纳秒，参见 ClockOffset；放在首位保证 32 位平台上原子操作的对齐
+	clockOffset int64
+
 	client *http.Client
 
 	Host      string
@@ -166,6 +174,8 @@ type Client struct {
 	Conf *Config
 
 	invalidURL bool
+	// SetEndpointResolver 解析出的地址
+	endpoints map[EndpointKind]*Endpoint
 }
 
 type service struct {
@@ -271,10 +281,14 @@ func (c *Client) newPresignedRequest(ctx context.Context, sendOpt *sendOptions,
 	if err != nil {
 		return
 	}
-	urlStr := fmt.Sprintf("%s://%s%s", sendOpt.baseURL.Scheme, sendOpt.baseURL.Host, sendOpt.uri)
+	urlStr := fmt.Sprintf("%s://%s%s%s", sendOpt.baseURL.Scheme, sendOpt.baseURL.Host, basePath(sendOpt.baseURL), sendOpt.uri)
 	if enablePathMerge {
 		u, _ := url.Parse(sendOpt.uri)
 		urlStr = sendOpt.baseURL.ResolveReference(u).String()
+		if prefix := basePath(sendOpt.baseURL); prefix != "" {
+			u, _ = url.Parse(urlStr)
+			urlStr = fmt.Sprintf("%s://%s%s%s", u.Scheme, u.Host, prefix, u.RequestURI())
+		}
 	}
 	req, err = http.NewRequest(sendOpt.method, urlStr, nil)
 	if err != nil {
@@ -285,6 +299,9 @@ func (c *Client) newPresignedRequest(ctx context.Context, sendOpt *sendOptions,
 	if err != nil {
 		return
 	}
+	if host := c.signingHost(sendOpt.baseURL); host != "" {
+		req.Host = host
+	}
 	if c.Host != "" {
 		req.Host = c.Host
 	}
@@ -298,7 +315,8 @@ func (c *Client) newRequest(ctx context.Context, baseURL *url.URL, uri, method s
 	if baseURL == nil {
 		return nil, invalidBucketErr
 	}
-	if !checkURL(baseURL) {
+	// 解析器生成的地址不做域名格式校验
+	if c.resolvedEndpoint(baseURL) == nil && !checkURL(baseURL) {
 		host := baseURL.String()
 		if c.BaseURL.MetaInsightURL != baseURL || !metaInsightHostPrefix.MatchString(host) {
 			return nil, invalidBucketErr
@@ -310,6 +328,9 @@ func (c *Client) newRequest(ctx context.Context, baseURL *url.URL, uri, method s
 	}
 	u, _ := url.Parse(uri)
 	urlStr := baseURL.ResolveReference(u).String()
+	if prefix := basePath(baseURL); prefix != "" && strings.HasPrefix(uri, "/") {
+		urlStr = fmt.Sprintf("%s://%s%s%s", baseURL.Scheme, baseURL.Host, prefix, uri)
+	}
 
 	var reader io.Reader
 	contentType := ""
@@ -363,6 +384,9 @@ func (c *Client) newRequest(ctx context.Context, baseURL *url.URL, uri, method s
 	if isRetry {
 		req.Header.Set("X-Cos-Sdk-Retry", "true")
 	}
+	if host := c.signingHost(baseURL); host != "" {
+		req.Host = host
+	}
 	if c.Host != "" {
 		req.Host = c.Host
 	}
@@ -391,6 +415,9 @@ func (c *Client) doAPI(ctx context.Context, req *http.Request, result interface{
 		}
 		return nil, err
 	}
+	if gotHeader, ok := ctx.Value(responseHeaderKey{}).(func()); ok {
+		gotHeader()
+	}
 
 	defer func() {
 		if closeBody {
@@ -425,6 +452,7 @@ func (c *Client) doAPI(ctx context.Context, req *http.Request, result interface{
 		}
 	}
 
+	c.Conf.RateLimit.limitDownload(ctx, resp)
 	if result != nil {
 		if w, ok := result.(io.Writer); ok {
 			_, err = io.Copy(w, resp.Body)
@@ -489,10 +517,14 @@ func (c *Client) CheckRetrieable(u *url.URL, resp *Response, err error, secondLa
 	if err != nil && err != invalidBucketErr {
 		// 不重试
 		if resp != nil && resp.StatusCode < 500 {
+			// 时钟偏差已校正，重新签名后重试
+			if c.signedWithStaleClock(resp, err) {
+				return res, true
+			}
 			if c.Conf.RetryOpt.AutoSwitchHost {
 				if resp.StatusCode == 301 || resp.StatusCode == 302 || resp.StatusCode == 307 {
 					if resp.Header.Get("X-Cos-Request-Id") == "" {
-						res = toSwitchHost(u)
+						res = c.switchHost(u)
 						if res != u {
 							return res, true
 						}
@@ -504,7 +536,7 @@ func (c *Client) CheckRetrieable(u *url.URL, resp *Response, err error, secondLa
 		if c.Conf.RetryOpt.AutoSwitchHost && secondLast {
 			// 收不到报文 或者 不存在RequestId
 			if resp == nil || resp.Header.Get("X-Cos-Request-Id") == "" {
-				res = toSwitchHost(u)
+				res = c.switchHost(u)
 			}
 		}
 		return res, true
@@ -531,7 +563,11 @@ func (c *Client) doRetry(ctx context.Context, opt *sendOptions) (resp *Response,
 			retryErr.Add(err)
 		}
 		opt.isRetry = nr > 0
-		resp, err = c.send(ctx, opt)
+		if c.hedgeable(opt) {
+			resp, err = c.sendHedged(ctx, opt)
+		} else {
+			resp, err = c.send(ctx, opt)
+		}
 		opt.baseURL, retrieable = c.CheckRetrieable(opt.baseURL, resp, err, nr >= count-2)
 		if retrieable {
 			if c.Conf.RetryOpt.Interval > 0 && nr+1 < count {
@@ -552,13 +588,25 @@ func (c *Client) doRetry(ctx context.Context, opt *sendOptions) (resp *Response,
 }
 
 func (c *Client) send(ctx context.Context, opt *sendOptions) (resp *Response, err error) {
-	req, err := c.newRequest(ctx, opt.baseURL, opt.uri, opt.method, opt.body, opt.optQuery, opt.optHeader, opt.isRetry)
-	if err != nil {
-		return
-	}
+	rewind := bodyRewinder(opt.body)
+	for nr := 0; ; nr++ {
+		sctx := c.withClockOffset(ctx)
+		if err = c.Conf.RateLimit.waitRequest(sctx, opt.method); err != nil {
+			return nil, err
+		}
+		req, err := c.newRequest(sctx, opt.baseURL, opt.uri, opt.method, opt.body, opt.optQuery, opt.optHeader, opt.isRetry)
+		if err != nil {
+			return nil, err
+		}
+		c.Conf.RateLimit.limitUpload(sctx, opt.body)
 
-	resp, err = c.doAPI(ctx, req, opt.result, !opt.disableCloseBody)
-	return
+		resp, err = c.doAPI(sctx, req, opt.result, !opt.disableCloseBody)
+		// 本地时钟偏差导致签名失败时，校正后重新签名并重试一次，无法 Seek 的流式 body 由调用方重试
+		if nr == 0 && c.correctClockSkew(resp, err) && rewind() {
+			continue
+		}
+		return resp, err
+	}
 }
 
 // addURLOptions adds the parameters in opt as URL query parameters to s. opt
@@ -633,6 +681,16 @@ func addHeaderOptions(ctx context.Context, header http.Header, opt interface{})
 	return header, nil
 }
 
+// basePath 返回 baseURL 中的路径前缀，不带末尾的 /。
+// path-style 地址（例如 http://127.0.0.1:9000/examplebucket-1250000000）返回 /examplebucket-1250000000，
+// virtual-host 地址返回空
+func basePath(baseURL *url.URL) string {
+	if baseURL == nil {
+		return ""
+	}
+	return strings.TrimRight(baseURL.EscapedPath(), "/")
+}
+
 func checkURL(baseURL *url.URL) bool {
 	if baseURL == nil {
 		return false
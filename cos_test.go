@@ -5,11 +5,13 @@ import (
 	"context"
 	"encoding/xml"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -633,3 +635,106 @@ func Test_BaseURL_InnerCheck(t *testing.T) {
 		t.Errorf("BaseURL with trailing slash should pass Check: %v", withSlash)
 	}
 }
+
+func Test_PathStyle(t *testing.T) {
+	var paths, sources []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.RequestURI())
+		if src := r.Header.Get("X-Cos-Copy-Source"); src != "" {
+			sources = append(sources, src)
+		}
+		// 按服务端收到的请求重新计算签名
+		auth := r.Header.Get("Authorization")
+		r.Header.Del("Authorization")
+		r.Header.Del("Accept-Encoding")
+		var start, end int64
+		for _, kv := range strings.Split(auth, "&") {
+			if strings.HasPrefix(kv, "q-sign-time=") {
+				fmt.Sscanf(kv, "q-sign-time=%d;%d", &start, &end)
+			}
+		}
+		authTime := &AuthTime{
+			SignStartTime: time.Unix(start, 0), SignEndTime: time.Unix(end, 0),
+			KeyStartTime: time.Unix(start, 0), KeyEndTime: time.Unix(end, 0),
+		}
+		if want := newAuthorization("ak", "sk", r, authTime, true); auth != "" && auth != want {
+			t.Errorf("%v %v Authorization: %v, want %v", r.Method, r.URL.Path, auth, want)
+		}
+		if r.URL.Query().Get("uploads") != "" || strings.HasSuffix(r.URL.RawQuery, "uploads") {
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>1</UploadId></InitiateMultipartUploadResult>`)
+		}
+		if r.Header.Get("X-Cos-Copy-Source") != "" {
+			fmt.Fprint(w, `<CopyObjectResult><ETag>"a"</ETag></CopyObjectResult>`)
+		}
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL + "/examplebucket-1250000000")
+	c := NewClient(&BaseURL{BucketURL: u}, &http.Client{
+		Transport: &AuthorizationTransport{SecretID: "ak", SecretKey: "sk"},
+	})
+	c.Conf.EnableCRC = false
+	ctx := context.Background()
+	if _, err := c.Bucket.Head(ctx); err != nil {
+		t.Fatalf("Bucket.Head returned error: %v", err)
+	}
+	if _, err := c.Object.Put(ctx, "dir/a b.txt", strings.NewReader("a"), nil); err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+	if _, _, err := c.Object.InitiateMultipartUpload(ctx, "dir/b", nil); err != nil {
+		t.Fatalf("Object.InitiateMultipartUpload returned error: %v", err)
+	}
+	want := []string{
+		"HEAD /examplebucket-1250000000/",
+		"PUT /examplebucket-1250000000/dir%2Fa%20b.txt",
+		"POST /examplebucket-1250000000/dir%2Fb?uploads",
+		"GET /examplebucket-1250000000/dir/a",
+		"GET /examplebucket-1250000000/dir/a",
+		"HEAD /examplebucket-1250000000/dir%2Fa%20b.txt?versionId=v1",
+		"PUT /examplebucket-1250000000/dir%2Fa%20b.txt",
+		"HEAD /examplebucket-1250000000/dir%2Fa%20b.txt",
+		"PUT /examplebucket-1250000000/dir%2Fc",
+	}
+
+	if got := c.Object.GetObjectURL("dir/a b.txt").String(); got != server.URL+"/examplebucket-1250000000/dir/a%20b.txt" {
+		t.Errorf("GetObjectURL returned %v", got)
+	}
+	for _, merge := range []bool{false, true} {
+		presigned, err := c.Object.GetPresignedURL(ctx, http.MethodGet, "dir/a", "ak", "sk", time.Hour, &PresignedURLOptions{EnablePathMerge: merge})
+		if err != nil || presigned.Path != "/examplebucket-1250000000/dir/a" {
+			t.Fatalf("GetPresignedURL returned %v, error: %v", presigned, err)
+		}
+		resp, err := http.Get(presigned.String())
+		if err != nil {
+			t.Fatalf("presigned Get returned error: %v", err)
+		}
+		resp.Body.Close()
+	}
+	// 拷贝源中 bucket 与对象键之间的 / 不编码
+	if _, _, err := c.Object.RestoreVersion(ctx, "dir/a b.txt", "v1"); err != nil {
+		t.Fatalf("Object.RestoreVersion returned error: %v", err)
+	}
+	if _, _, err := c.Object.MultiCopy(ctx, "dir/c", u.Host+"/examplebucket-1250000000/dir/a b.txt", nil); err != nil {
+		t.Fatalf("Object.MultiCopy returned error: %v", err)
+	}
+	for i := range paths {
+		paths[i] = strings.SplitN(paths[i], "?q-", 2)[0]
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("request paths: %v, want %v", paths, want)
+	}
+	wantSources := []string{
+		u.Host + "/examplebucket-1250000000/dir%2Fa%20b.txt?versionId=v1",
+		u.Host + "/examplebucket-1250000000/dir/a%20b.txt",
+	}
+	if !reflect.DeepEqual(sources, wantSources) {
+		t.Errorf("copy sources: %v, want %v", sources, wantSources)
+	}
+
+	ep, _ := DefaultEndpointResolver{}.ResolveEndpoint(EndpointBucket, &EndpointOptions{
+		Bucket: "examplebucket-1250000000", Scheme: "http", CustomDomain: "127.0.0.1:9000", PathStyle: true,
+	})
+	if ep.URL.String() != "http://127.0.0.1:9000/examplebucket-1250000000" {
+		t.Errorf("ResolveEndpoint with PathStyle returned %v", ep.URL)
+	}
+}
@@ -0,0 +1,430 @@
+// Package fault 提供注入故障的 http.RoundTripper，用于在测试中验证重试、断点续传和校验逻辑。
+//
+// 支持的故障包括延迟、连接重置、响应体截断、5xx 和限流响应、缺失 X-Cos-Request-Id
+// 以及数据损坏，可以按操作、对象名、概率或者重试次数选择注入的请求。
+package fault
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Kind 是故障的类型
+type Kind string
+
+const (
+	// Latency 在发送请求前等待 Rule.Latency
+	Latency Kind = "latency"
+	// Reset 不发送请求，返回连接重置错误
+	Reset Kind = "reset"
+	// TruncateBody 在读取 Rule.Offset 字节后中断响应体，默认为响应体的一半
+	TruncateBody Kind = "truncate"
+	// ServerError 不发送请求，返回 5xx 错误，默认为 500 InternalError
+	ServerError Kind = "5xx"
+	// Throttle 不发送请求，返回限流错误，默认为 503 SlowDown
+	Throttle Kind = "throttle"
+	// DropRequestID 移除真实响应中的 X-Cos-Request-Id
+	DropRequestID Kind = "drop-request-id"
+	// CorruptBody 翻转响应体中 Rule.Offset 处的一个字节，下载时会导致 CRC64 校验失败
+	CorruptBody Kind = "corrupt"
+	// CorruptRequest 翻转请求体中 Rule.Offset 处的一个字节，上传时会导致 CRC64 校验失败
+	CorruptRequest Kind = "corrupt-request"
+)
+
+// 常用的 COS 操作名，由 Operation 根据请求推断
+const (
+	OpHeadObject              = "HeadObject"
+	OpGetObject               = "GetObject"
+	OpPutObject               = "PutObject"
+	OpCopyObject              = "CopyObject"
+	OpDeleteObject            = "DeleteObject"
+	OpInitiateMultipartUpload = "InitiateMultipartUpload"
+	OpUploadPart              = "UploadPart"
+	OpUploadPartCopy          = "UploadPartCopy"
+	OpCompleteMultipartUpload = "CompleteMultipartUpload"
+	OpAbortMultipartUpload    = "AbortMultipartUpload"
+	OpListParts               = "ListParts"
+	OpGetBucket               = "GetBucket"
+	OpDeleteObjects           = "DeleteObjects"
+)
+
+// ErrConnectionReset 是 Reset 故障返回的错误
+var ErrConnectionReset = fmt.Errorf("fault: injected connection reset: %w", syscall.ECONNRESET)
+
+// Rule 描述一种故障以及它作用的请求，所有条件同时满足时注入
+type Rule struct {
+	Kind Kind
+	// 操作名，为空时匹配所有操作，参考 Op* 常量
+	Operations []string
+	// 对象名的 path.Match 模式，为空时匹配所有对象
+	KeyPattern string
+	// 域名的 path.Match 模式，为空时匹配所有域名
+	Host string
+	// 注入概率，nil 表示总是注入，0 表示从不注入，可以使用 Chance 设置
+	Probability *float64
+	// 只在这些尝试次数上注入，从 1 开始计数；同一个 method 和 URL 的请求视为同一请求的重试
+	Attempts []int
+	// 最多注入的次数，0 表示不限制
+	MaxTimes int
+
+	// Latency 的等待时间
+	Latency time.Duration
+	// ServerError/Throttle 的状态码和错误码
+	StatusCode int
+	Code       string
+	// ServerError/Throttle 的响应中不包含 X-Cos-Request-Id
+	OmitRequestID bool
+	// TruncateBody/CorruptBody/CorruptRequest 的偏移
+	Offset int64
+
+	times int
+}
+
+// Chance 返回 Rule.Probability 使用的注入概率
+func Chance(p float64) *float64 {
+	return &p
+}
+
+// Injection 是一次故障注入的记录
+type Injection struct {
+	Kind      Kind
+	Operation string
+	Method    string
+	URL       string
+	Attempt   int
+}
+
+// Transport 按 Rules 注入故障，每个请求只注入第一个命中的规则，可并发使用
+type Transport struct {
+	Rules []*Rule
+	// 实际发送请求的 Transport，默认是 http.DefaultTransport
+	Transport http.RoundTripper
+	// 随机数种子，用于复现概率注入的结果
+	Seed int64
+
+	mu        sync.Mutex
+	rnd       *rand.Rand
+	attempts  map[string]int
+	injection []Injection
+}
+
+// Injections 返回已注入的故障
+func (t *Transport) Injections() []Injection {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Injection(nil), t.injection...)
+}
+
+// Reset 清空注入记录和尝试次数
+func (t *Transport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.injection = nil
+	t.attempts = nil
+	for _, r := range t.Rules {
+		r.times = 0
+	}
+}
+
+// RoundTrip implements the RoundTripper interface.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rule := t.match(req)
+	if rule == nil {
+		return t.transport().RoundTrip(req)
+	}
+	switch rule.Kind {
+	case Latency:
+		timer := time.NewTimer(rule.Latency)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+		return t.transport().RoundTrip(req)
+	case Reset:
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, ErrConnectionReset
+	case ServerError, Throttle:
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return errorResponse(req, rule), nil
+	case CorruptRequest:
+		if req.Body != nil && req.Body != http.NoBody {
+			r := *req
+			r.Body = &corruptReader{rc: req.Body, offset: rule.Offset}
+			req = &r
+		}
+		return t.transport().RoundTrip(req)
+	}
+
+	resp, err := t.transport().RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	switch rule.Kind {
+	case DropRequestID:
+		resp.Header.Del("X-Cos-Request-Id")
+	case CorruptBody:
+		resp.Body = &corruptReader{rc: resp.Body, offset: rule.Offset}
+	case TruncateBody:
+		offset := rule.Offset
+		if offset <= 0 {
+			offset = resp.ContentLength / 2
+		}
+		resp.Body = &truncateReader{rc: resp.Body, remain: offset}
+	}
+	return resp, nil
+}
+
+func (t *Transport) transport() http.RoundTripper {
+	if t.Transport != nil {
+		return t.Transport
+	}
+	return http.DefaultTransport
+}
+
+// match 记录请求的尝试次数，返回第一个命中的规则
+func (t *Transport) match(req *http.Request) *Rule {
+	op := Operation(req)
+	key := objectKey(req.URL)
+	id := req.Method + " " + req.URL.Path + "?" + req.URL.RawQuery
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.attempts == nil {
+		t.attempts = make(map[string]int)
+	}
+	if t.rnd == nil {
+		t.rnd = rand.New(rand.NewSource(t.Seed))
+	}
+	t.attempts[id]++
+	attempt := t.attempts[id]
+	for _, r := range t.Rules {
+		if !r.matches(op, key, req.URL.Host, attempt) {
+			continue
+		}
+		if r.Probability != nil && t.rnd.Float64() >= *r.Probability {
+			continue
+		}
+		r.times++
+		t.injection = append(t.injection, Injection{
+			Kind:      r.Kind,
+			Operation: op,
+			Method:    req.Method,
+			URL:       req.URL.String(),
+			Attempt:   attempt,
+		})
+		return r
+	}
+	return nil
+}
+
+func (r *Rule) matches(op, key, host string, attempt int) bool {
+	if r.MaxTimes > 0 && r.times >= r.MaxTimes {
+		return false
+	}
+	if len(r.Operations) > 0 && !contains(r.Operations, op) {
+		return false
+	}
+	if r.KeyPattern != "" {
+		if ok, _ := path.Match(r.KeyPattern, key); !ok {
+			return false
+		}
+	}
+	if r.Host != "" {
+		if ok, _ := path.Match(r.Host, host); !ok {
+			return false
+		}
+	}
+	if len(r.Attempts) > 0 {
+		found := false
+		for _, a := range r.Attempts {
+			if a == attempt {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func objectKey(u *url.URL) string {
+	return strings.TrimPrefix(u.Path, "/")
+}
+
+// Operation 根据 method、路径和子资源推断请求对应的 COS 操作，无法识别时返回 "<METHOD> <子资源>"
+func Operation(req *http.Request) string {
+	q := req.URL.Query()
+	_, uploadId := q["uploadId"]
+	_, partNumber := q["partNumber"]
+	// SDK 总是将子资源放在第一个参数
+	sub := subresource(req.URL.RawQuery)
+	if objectKey(req.URL) == "" {
+		switch {
+		case req.Method == http.MethodGet && sub == "":
+			return OpGetBucket
+		case req.Method == http.MethodPost && sub == "delete":
+			return OpDeleteObjects
+		}
+		return req.Method + " " + sub
+	}
+	switch req.Method {
+	case http.MethodHead:
+		return OpHeadObject
+	case http.MethodGet:
+		if uploadId {
+			return OpListParts
+		}
+		if sub == "" {
+			return OpGetObject
+		}
+	case http.MethodPut:
+		isCopy := req.Header.Get("x-cos-copy-source") != ""
+		switch {
+		case partNumber && uploadId && isCopy:
+			return OpUploadPartCopy
+		case partNumber && uploadId:
+			return OpUploadPart
+		case sub == "" && isCopy:
+			return OpCopyObject
+		case sub == "":
+			return OpPutObject
+		}
+	case http.MethodPost:
+		switch {
+		case sub == "uploads":
+			return OpInitiateMultipartUpload
+		case uploadId:
+			return OpCompleteMultipartUpload
+		}
+	case http.MethodDelete:
+		if uploadId {
+			return OpAbortMultipartUpload
+		}
+		if sub == "" {
+			return OpDeleteObject
+		}
+	}
+	return req.Method + " " + sub
+}
+
+// subresource 返回没有值的第一个参数，例如 ?uploads、?tagging
+func subresource(rawQuery string) string {
+	kv := strings.SplitN(strings.SplitN(rawQuery, "&", 2)[0], "=", 2)
+	if kv[0] == "" || (len(kv) == 2 && kv[1] != "") {
+		return ""
+	}
+	return kv[0]
+}
+
+func errorResponse(req *http.Request, r *Rule) *http.Response {
+	status, code := r.StatusCode, r.Code
+	if r.Kind == Throttle {
+		if status == 0 {
+			status = http.StatusServiceUnavailable
+		}
+		if code == "" {
+			code = "SlowDown"
+		}
+	}
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	if code == "" {
+		code = "InternalError"
+	}
+	body := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+	<Code>%s</Code>
+	<Message>fault injected</Message>
+	<Resource>%s</Resource>
+	<RequestId>fault-injected</RequestId>
+</Error>`, code, req.URL.Path)
+	header := http.Header{}
+	header.Set("Content-Type", "application/xml")
+	if !r.OmitRequestID {
+		header.Set("X-Cos-Request-Id", "fault-injected")
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewBufferString(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// truncateReader 读取 remain 字节后返回 io.ErrUnexpectedEOF
+type truncateReader struct {
+	rc     io.ReadCloser
+	remain int64
+}
+
+func (r *truncateReader) Read(p []byte) (int, error) {
+	if r.remain <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if int64(len(p)) > r.remain {
+		p = p[:r.remain]
+	}
+	n, err := r.rc.Read(p)
+	r.remain -= int64(n)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (r *truncateReader) Close() error {
+	return r.rc.Close()
+}
+
+// corruptReader 翻转 offset 处的一个字节
+type corruptReader struct {
+	rc     io.ReadCloser
+	offset int64
+	pos    int64
+}
+
+func (r *corruptReader) Read(p []byte) (int, error) {
+	n, err := r.rc.Read(p)
+	if r.offset >= r.pos && r.offset < r.pos+int64(n) {
+		p[r.offset-r.pos] ^= 0xff
+	}
+	r.pos += int64(n)
+	return n, err
+}
+
+func (r *corruptReader) Close() error {
+	return r.rc.Close()
+}
@@ -0,0 +1,176 @@
+package fault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+var data = []byte(strings.Repeat("0123456789", 100))
+
+func setup(t *testing.T, rules ...*Rule) (*cos.Client, *Transport, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Cos-Request-Id", "real")
+		w.Header().Set("x-cos-hash-crc64ecma", strconv.FormatUint(crc64.Checksum(data, crc64.MakeTable(crc64.ECMA)), 10))
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	}))
+	ft := &Transport{Rules: rules, Seed: 1}
+	u, _ := url.Parse(server.URL)
+	c := cos.NewClient(&cos.BaseURL{BucketURL: u}, &http.Client{Transport: ft})
+	return c, ft, server.Close
+}
+
+func TestOperation(t *testing.T) {
+	cases := []struct {
+		method, url string
+		copy        bool
+		want        string
+	}{
+		{"HEAD", "/a/b", false, OpHeadObject},
+		{"GET", "/a/b?versionId=1", false, OpGetObject},
+		{"GET", "/a/b?uploadId=1", false, OpListParts},
+		{"GET", "/a/b?tagging", false, "GET tagging"},
+		{"PUT", "/a", false, OpPutObject},
+		{"PUT", "/a", true, OpCopyObject},
+		{"PUT", "/a?partNumber=1&uploadId=2", false, OpUploadPart},
+		{"PUT", "/a?partNumber=1&uploadId=2", true, OpUploadPartCopy},
+		{"POST", "/a?uploads", false, OpInitiateMultipartUpload},
+		{"POST", "/a?uploadId=2", false, OpCompleteMultipartUpload},
+		{"DELETE", "/a?uploadId=2", false, OpAbortMultipartUpload},
+		{"DELETE", "/a", false, OpDeleteObject},
+		{"GET", "/?prefix=a", false, OpGetBucket},
+		{"GET", "/?versions&prefix=a", false, "GET versions"},
+		{"POST", "/?delete", false, OpDeleteObjects},
+	}
+	for _, c := range cases {
+		req, _ := http.NewRequest(c.method, "http://example.com"+c.url, nil)
+		if c.copy {
+			req.Header.Set("x-cos-copy-source", "example.com/b")
+		}
+		if got := Operation(req); got != c.want {
+			t.Errorf("Operation(%v %v) = %v, want %v", c.method, c.url, got, c.want)
+		}
+	}
+}
+
+func TestTransport_ServerErrorRetried(t *testing.T) {
+	c, ft, done := setup(t,
+		&Rule{Kind: ServerError, Operations: []string{OpHeadObject}, Attempts: []int{1}},
+		&Rule{Kind: Throttle, Operations: []string{OpHeadObject}, Attempts: []int{2}},
+	)
+	defer done()
+
+	if _, err := c.Object.Head(context.Background(), "a", nil); err != nil {
+		t.Fatalf("Object.Head returned error: %v", err)
+	}
+	inj := ft.Injections()
+	if len(inj) != 2 || inj[0].Kind != ServerError || inj[1].Kind != Throttle || inj[1].Attempt != 2 {
+		t.Errorf("Injections returned %+v", inj)
+	}
+
+	// 所有重试都失败时返回最后一次的 COS 错误
+	ft.Reset()
+	ft.Rules = []*Rule{{Kind: Throttle, KeyPattern: "dir/*"}}
+	_, err := c.Object.Head(context.Background(), "dir/a", nil)
+	if e, ok := cos.IsCOSError(err); !ok || e.Code != "SlowDown" || e.Response.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Object.Head returned error: %v", err)
+	}
+	if len(ft.Injections()) != 3 {
+		t.Errorf("Injections returned %v, want 3", len(ft.Injections()))
+	}
+}
+
+func TestTransport_Reset(t *testing.T) {
+	c, ft, done := setup(t, &Rule{Kind: Reset, MaxTimes: 1})
+	defer done()
+
+	if _, err := c.Object.Head(context.Background(), "a", nil); err != nil {
+		t.Fatalf("Object.Head returned error: %v", err)
+	}
+	ft.Reset()
+	ft.Rules[0].MaxTimes = 0
+	_, err := c.Object.Head(context.Background(), "a", nil)
+	if !errors.Is(err, syscall.ECONNRESET) && (err == nil || !strings.Contains(err.Error(), "connection reset")) {
+		t.Errorf("Object.Head returned error: %v", err)
+	}
+}
+
+func TestTransport_Body(t *testing.T) {
+	c, _, done := setup(t,
+		&Rule{Kind: TruncateBody, KeyPattern: "truncate"},
+		&Rule{Kind: CorruptBody, KeyPattern: "corrupt", Offset: 10},
+		&Rule{Kind: DropRequestID, KeyPattern: "drop"},
+		&Rule{Kind: Latency, KeyPattern: "slow", Latency: 50 * time.Millisecond},
+	)
+	defer done()
+
+	resp, err := c.Object.Get(context.Background(), "truncate", nil)
+	if err != nil {
+		t.Fatalf("Object.Get returned error: %v", err)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != io.ErrUnexpectedEOF || len(b) != len(data)/2 {
+		t.Errorf("truncated body read %v bytes, error: %v", len(b), err)
+	}
+
+	filePath := fmt.Sprintf("fault_%d", time.Now().UnixNano())
+	defer os.Remove(filePath)
+	if _, err = c.Object.GetToFile(context.Background(), "corrupt", filePath, nil); err == nil || !strings.Contains(err.Error(), "crc64") {
+		t.Errorf("Object.GetToFile with corrupted body returned error: %v", err)
+	}
+
+	resp, err = c.Object.Head(context.Background(), "drop", nil)
+	if err != nil || resp.Header.Get("X-Cos-Request-Id") != "" {
+		t.Errorf("Object.Head returned request id %q, error: %v", resp.Header.Get("X-Cos-Request-Id"), err)
+	}
+
+	start := time.Now()
+	if _, err = c.Object.Head(context.Background(), "slow", nil); err != nil || time.Since(start) < 50*time.Millisecond {
+		t.Errorf("Object.Head with latency took %v, error: %v", time.Since(start), err)
+	}
+}
+
+func TestTransport_Probability(t *testing.T) {
+	count := func() int {
+		c, ft, done := setup(t, &Rule{Kind: ServerError, Probability: Chance(0.5)})
+		defer done()
+		c.Conf.RetryOpt.Count = 1
+		for i := 0; i < 20; i++ {
+			c.Object.Head(context.Background(), "a", nil)
+		}
+		return len(ft.Injections())
+	}
+	n := count()
+	if n == 0 || n == 20 || n != count() {
+		t.Errorf("probability injections %v are not reproducible", n)
+	}
+
+	c, ft, done := setup(t, &Rule{Kind: ServerError, Probability: Chance(0)})
+	defer done()
+	for i := 0; i < 20; i++ {
+		if _, err := c.Object.Head(context.Background(), "a", nil); err != nil {
+			t.Errorf("Object.Head with zero probability returned error: %v", err)
+		}
+	}
+	if len(ft.Injections()) != 0 {
+		t.Errorf("zero probability injected %v faults", len(ft.Injections()))
+	}
+}
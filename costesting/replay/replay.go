@@ -0,0 +1,364 @@
+// Package replay 提供录制/回放 COS 请求的 http.RoundTripper，用于编写不依赖网络的确定性测试。
+//
+// 录制模式下请求经由真实的 Transport 发送，请求和响应被记录到 cassette 文件；
+// 回放模式下按 method、path、排序后的 query 以及指定的头部匹配录制的交互并直接返回响应。
+// 签名相关的 Authorization、q-key-time 等易变字段不参与匹配，也不会写入 cassette。
+package replay
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Mode 是 Transport 的工作模式
+type Mode int
+
+const (
+	// ModeReplay 只回放，找不到匹配的交互时返回错误
+	ModeReplay Mode = iota
+	// ModeRecord 发送真实请求并录制，Save 时覆盖 cassette 文件
+	ModeRecord
+	// ModeAuto cassette 文件存在时回放，否则录制
+	ModeAuto
+)
+
+// Redacted 是敏感信息在 cassette 中的替换值
+const Redacted = "REDACTED"
+
+// 不参与匹配、也不会被录制的易变头部
+var volatileHeaders = map[string]bool{
+	"Authorization":        true,
+	"Date":                 true,
+	"X-Cos-Security-Token": true,
+	"X-Date":               true,
+	"User-Agent":           true,
+}
+
+// 预签名 URL 中的签名参数，不参与匹配
+var volatileQuery = map[string]bool{
+	"q-sign-algorithm":     true,
+	"q-ak":                 true,
+	"q-sign-time":          true,
+	"q-key-time":           true,
+	"q-header-list":        true,
+	"q-url-param-list":     true,
+	"q-signature":          true,
+	"x-cos-security-token": true,
+}
+
+// Request 是录制的请求
+type Request struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"bodyEncoding,omitempty"`
+}
+
+// Response 是录制的响应
+type Response struct {
+	StatusCode   int         `json:"statusCode"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"bodyEncoding,omitempty"`
+}
+
+// Interaction 是一次请求和它的响应
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette 是 cassette 文件的内容
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Transport 实现了录制/回放的 http.RoundTripper，可并发使用。
+//
+// 在 cos.AuthorizationTransport 之下使用时，录制的请求包含签名，签名会在写入前被移除：
+//
+//	rt, _ := replay.New("testdata/upload.json", replay.ModeAuto)
+//	defer rt.Save()
+//	c := cos.NewClient(b, &http.Client{Transport: &cos.AuthorizationTransport{
+//		SecretID: id, SecretKey: key, Transport: rt,
+//	}})
+type Transport struct {
+	Mode Mode
+	// cassette 文件路径
+	Path string
+	// 录制时使用的 Transport，默认是 http.DefaultTransport
+	Transport http.RoundTripper
+	// 除 method、path 和 query 之外参与匹配的头部
+	MatchHeaders []string
+	// 是否按请求体匹配，同一接口使用不同请求体时（例如 CI 任务提交）需要开启
+	MatchBody bool
+	// 录制时替换为 Redacted 的敏感字符串，例如 SecretId、APPID
+	Secrets []string
+	// 录制时额外移除的头部
+	ScrubHeaders []string
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// New 创建 Transport，回放模式下会加载 cassette 文件
+func New(path string, mode Mode) (*Transport, error) {
+	t := &Transport{Mode: mode, Path: path, cassette: &Cassette{}}
+	if mode == ModeAuto {
+		t.Mode = ModeRecord
+		if _, err := os.Stat(path); err == nil {
+			t.Mode = ModeReplay
+		}
+	}
+	if t.Mode == ModeReplay {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(b, t.cassette); err != nil {
+			return nil, fmt.Errorf("replay: invalid cassette %v: %v", path, err)
+		}
+		t.used = make([]bool, len(t.cassette.Interactions))
+	}
+	return t, nil
+}
+
+// Recording 是否处于录制模式
+func (t *Transport) Recording() bool {
+	return t.Mode == ModeRecord
+}
+
+// RoundTrip implements the RoundTripper interface.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if t.Mode == ModeRecord {
+		return t.record(req, body)
+	}
+	return t.replay(req, body)
+}
+
+func (t *Transport) record(req *http.Request, body []byte) (*http.Response, error) {
+	rt := t.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	it := &Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    t.scrub(scrubURL(req.URL)),
+			Header: t.scrubHeader(req.Header),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     t.scrubHeader(resp.Header),
+		},
+	}
+	it.Request.Body, it.Request.BodyEncoding = t.encodeBody(body)
+	it.Response.Body, it.Response.BodyEncoding = t.encodeBody(b)
+	t.mu.Lock()
+	t.cassette.Interactions = append(t.cassette.Interactions, it)
+	t.mu.Unlock()
+	return resp, nil
+}
+
+func (t *Transport) replay(req *http.Request, body []byte) (*http.Response, error) {
+	key := t.matchKey(req.Method, scrubURL(req.URL), req.Header, body, false)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// 相同的请求按录制的顺序依次回放
+	for i, it := range t.cassette.Interactions {
+		if t.used[i] {
+			continue
+		}
+		reqBody, err := decodeBody(it.Request.Body, it.Request.BodyEncoding)
+		if err != nil {
+			return nil, err
+		}
+		if t.matchKey(it.Request.Method, it.Request.URL, it.Request.Header, reqBody, true) != key {
+			continue
+		}
+		t.used[i] = true
+		respBody, err := decodeBody(it.Response.Body, it.Response.BodyEncoding)
+		if err != nil {
+			return nil, err
+		}
+		header := http.Header{}
+		for k, v := range it.Response.Header {
+			header[k] = append([]string(nil), v...)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", it.Response.StatusCode, http.StatusText(it.Response.StatusCode)),
+			StatusCode:    it.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader(respBody)),
+			ContentLength: int64(len(respBody)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("replay: no recorded interaction for %v %v", req.Method, scrubURL(req.URL))
+}
+
+// Save 在录制模式下将录制的交互写入 cassette 文件，回放模式下不做任何操作
+func (t *Transport) Save() error {
+	if t.Mode != ModeRecord {
+		return nil
+	}
+	t.mu.Lock()
+	b, err := json.MarshalIndent(t.cassette, "", "  ")
+	t.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(t.Path); dir != "" {
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return ioutil.WriteFile(t.Path, b, 0644)
+}
+
+// Unused 返回回放模式下尚未被使用的交互，可用于检查测试是否覆盖了完整的录制流程
+func (t *Transport) Unused() []*Interaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var res []*Interaction
+	for i, it := range t.cassette.Interactions {
+		if !t.used[i] {
+			res = append(res, it)
+		}
+	}
+	return res
+}
+
+// matchKey 生成匹配用的 key，录制的值已经过脱敏，因此请求的值需要做同样的替换
+func (t *Transport) matchKey(method, rawURL string, header http.Header, body []byte, recorded bool) string {
+	if !recorded {
+		rawURL = t.scrub(rawURL)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return method + " " + rawURL
+	}
+	var sb strings.Builder
+	sb.WriteString(method)
+	sb.WriteString(" ")
+	sb.WriteString(u.EscapedPath())
+	sb.WriteString("?")
+	// url.Values.Encode 按 key 排序
+	sb.WriteString(u.Query().Encode())
+	for _, k := range t.MatchHeaders {
+		v := header.Get(k)
+		if !recorded {
+			v = t.scrub(v)
+		}
+		sb.WriteString("\n" + http.CanonicalHeaderKey(k) + ": " + v)
+	}
+	if t.MatchBody {
+		b := string(body)
+		if !recorded {
+			b = t.scrub(b)
+		}
+		sb.WriteString("\n\n" + b)
+	}
+	return sb.String()
+}
+
+func (t *Transport) scrub(s string) string {
+	for _, secret := range t.Secrets {
+		if secret != "" {
+			s = strings.Replace(s, secret, Redacted, -1)
+		}
+	}
+	return s
+}
+
+func (t *Transport) scrubHeader(h http.Header) http.Header {
+	res := http.Header{}
+	for k, v := range h {
+		if volatileHeaders[http.CanonicalHeaderKey(k)] {
+			continue
+		}
+		res[k] = make([]string, len(v))
+		for i := range v {
+			res[k][i] = t.scrub(v[i])
+		}
+	}
+	for _, k := range t.ScrubHeaders {
+		res.Del(k)
+	}
+	return res
+}
+
+func (t *Transport) encodeBody(b []byte) (string, string) {
+	if utf8.Valid(b) {
+		return t.scrub(string(b)), ""
+	}
+	return base64.StdEncoding.EncodeToString(b), "base64"
+}
+
+func decodeBody(body, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return []byte(body), nil
+	case "base64":
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return nil, fmt.Errorf("replay: unknown body encoding %v", encoding)
+}
+
+// scrubURL 移除 URL 中的签名参数，其余参数按 key 排序
+func scrubURL(u *url.URL) string {
+	q := u.Query()
+	for k := range q {
+		if volatileQuery[strings.ToLower(k)] {
+			q.Del(k)
+		}
+	}
+	v := *u
+	v.RawQuery = q.Encode()
+	v.User = nil
+	return v.String()
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	b, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, errors.New("replay: read request body failed: " + err.Error())
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(b))
+	return b, nil
+}
@@ -0,0 +1,138 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+const (
+	testSecretID  = "AKIDtestsecretid"
+	testSecretKey = "testsecretkey"
+)
+
+func newClient(rawURL string, rt http.RoundTripper) *cos.Client {
+	u, _ := url.Parse(rawURL)
+	c := cos.NewClient(&cos.BaseURL{BucketURL: u}, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:  testSecretID,
+			SecretKey: testSecretKey,
+			Transport: rt,
+		},
+	})
+	c.Conf.EnableCRC = false
+	return c
+}
+
+func runFlow(c *cos.Client) error {
+	if _, err := c.Object.Put(context.Background(), "dir/a.txt", strings.NewReader("hello"), nil); err != nil {
+		return err
+	}
+	for i := 0; i < 2; i++ {
+		resp, err := c.Object.Get(context.Background(), "dir/a.txt", nil)
+		if err != nil {
+			return err
+		}
+		b, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if want := fmt.Sprintf("hello %d", i); string(b) != want {
+			return fmt.Errorf("Get returned %q, want %q", b, want)
+		}
+	}
+	return nil
+}
+
+func TestTransport_RecordReplay(t *testing.T) {
+	var gets int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-cos-request-id", "req-"+testSecretID)
+		if r.Method == http.MethodGet {
+			fmt.Fprintf(w, "hello %d", gets)
+			gets++
+		}
+	}))
+	dir, _ := ioutil.TempDir("", "replay")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "testdata", "flow.json")
+
+	rt, err := New(path, ModeAuto)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if !rt.Recording() {
+		t.Fatalf("New with missing cassette expect recording")
+	}
+	rt.Secrets = []string{testSecretID}
+	if err = runFlow(newClient(server.URL, rt)); err != nil {
+		t.Fatalf("record flow returned error: %v", err)
+	}
+	if err = rt.Save(); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	server.Close()
+
+	b, _ := ioutil.ReadFile(path)
+	for _, s := range []string{testSecretID, "q-sign-time", "Authorization"} {
+		if strings.Contains(string(b), s) {
+			t.Errorf("cassette contains %q", s)
+		}
+	}
+
+	rt, err = New(path, ModeAuto)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	rt.Secrets = []string{testSecretID}
+	c := newClient(server.URL, rt)
+	if err = runFlow(c); err != nil {
+		t.Fatalf("replay flow returned error: %v", err)
+	}
+	if len(rt.Unused()) != 0 {
+		t.Errorf("replay left %v unused interactions", len(rt.Unused()))
+	}
+	if _, err = c.Object.Head(context.Background(), "dir/a.txt", nil); err == nil {
+		t.Errorf("replay without recorded interaction expect error")
+	}
+}
+
+func TestTransport_MatchQueryAndBody(t *testing.T) {
+	rt := &Transport{Mode: ModeReplay, MatchBody: true, MatchHeaders: []string{"x-cos-meta-a"}}
+	rt.cassette = &Cassette{Interactions: []*Interaction{
+		{
+			Request:  Request{Method: "POST", URL: "http://example.com/jobs?b=2&a=1", Header: http.Header{"X-Cos-Meta-A": {"1"}}, Body: "<Request>1</Request>"},
+			Response: Response{StatusCode: 200, Body: "job1"},
+		},
+		{
+			Request:  Request{Method: "POST", URL: "http://example.com/jobs?a=1&b=2", Header: http.Header{"X-Cos-Meta-A": {"1"}}, Body: "<Request>2</Request>"},
+			Response: Response{StatusCode: 200, Body: "job2"},
+		},
+	}}
+	rt.used = make([]bool, 2)
+
+	do := func(body string) string {
+		req, _ := http.NewRequest("POST", "http://example.com/jobs?a=1&b=2&q-sign-time=1;2&q-key-time=1;2", strings.NewReader(body))
+		req.Header.Set("x-cos-meta-a", "1")
+		req.Header.Set("Authorization", "q-sign-algorithm=sha1")
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			return err.Error()
+		}
+		b, _ := ioutil.ReadAll(resp.Body)
+		return string(b)
+	}
+	if got := do("<Request>2</Request>"); got != "job2" {
+		t.Errorf("RoundTrip returned %v, want job2", got)
+	}
+	if got := do("<Request>1</Request>"); got != "job1" {
+		t.Errorf("RoundTrip returned %v, want job1", got)
+	}
+}
@@ -0,0 +1,292 @@
+package cos
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// EndpointKind 是需要解析地址的服务类型，对应 BaseURL 中的各个字段
+type EndpointKind string
+
+const (
+	// Bucket 和 Object 相关 API，对应 BaseURL.BucketURL
+	EndpointBucket EndpointKind = "bucket"
+	// Service API，对应 BaseURL.ServiceURL
+	EndpointService EndpointKind = "service"
+	// 批量处理 API，对应 BaseURL.BatchURL
+	EndpointBatch EndpointKind = "batch"
+	// 数据万象 API，对应 BaseURL.CIURL
+	EndpointCI EndpointKind = "ci"
+	// 数据迁移 Fetch Task，对应 BaseURL.FetchURL
+	EndpointFetch EndpointKind = "fetch"
+	// MetaInsight，对应 BaseURL.MetaInsightURL
+	EndpointMetaInsight EndpointKind = "metainsight"
+	// 向量桶，对应 BaseURL.VectorURL
+	EndpointVector EndpointKind = "vector"
+)
+
+var endpointKinds = []EndpointKind{
+	EndpointBucket, EndpointService, EndpointBatch, EndpointCI,
+	EndpointFetch, EndpointMetaInsight, EndpointVector,
+}
+
+// ErrEndpointNotSupported 表示解析器不提供该服务的地址，SetEndpointResolver 会保留原有地址
+var ErrEndpointNotSupported = errors.New("endpoint not supported")
+
+// EndpointOptions 是解析地址所需的参数
+type EndpointOptions struct {
+	// 存储桶名称，格式为 {name}-{appid}
+	Bucket string
+	Region string
+	// MetaInsight 使用，为空时从 Bucket 中获取
+	AppId string
+	// 批量处理使用
+	UIN string
+	// http 或 https，默认为 https
+	Scheme string
+	// 全球加速域名
+	Accelerate bool
+	// 内网域名
+	Internal bool
+	// IPv4/IPv6 双栈域名
+	DualStack bool
+	// 静态网站域名
+	Website bool
+	// 默认 CDN 加速域名，设置 CustomDomain 时使用 CustomDomain
+	CDN bool
+	// 存储桶的自定义域名，可以带 scheme，例如 https://static.example.com
+	CustomDomain string
+	// 使用 path-style 访问存储桶：{scheme}://{host}/{bucket}，host 为 CustomDomain，
+	// 未设置时为 cos.{region}.myqcloud.com。适用于本地的 COS/S3 兼容服务，例如 http://127.0.0.1:9000
+	PathStyle bool
+}
+
+// Endpoint 是解析出的服务地址
+type Endpoint struct {
+	URL *url.URL
+	// 签名和 Host 头部使用的域名，为空时使用 URL 中的域名；
+	// 通过 IP 或者私有网络地址访问时设置为存储桶的域名
+	SigningHost string
+	// 开启 AutoSwitchHost 时重试使用的备用地址，为 nil 时不切换
+	Fallback *url.URL
+}
+
+// EndpointResolver 根据服务类型和参数解析服务地址
+type EndpointResolver interface {
+	ResolveEndpoint(kind EndpointKind, opt *EndpointOptions) (*Endpoint, error)
+}
+
+// EndpointResolverFunc 将函数适配为 EndpointResolver
+type EndpointResolverFunc func(kind EndpointKind, opt *EndpointOptions) (*Endpoint, error)
+
+func (f EndpointResolverFunc) ResolveEndpoint(kind EndpointKind, opt *EndpointOptions) (*Endpoint, error) {
+	return f(kind, opt)
+}
+
+// DefaultEndpointResolver 按 COS 公有云的域名规则生成地址：
+//
+//	bucket:      {bucket}.cos.{region}.myqcloud.com，PathStyle 时为 cos.{region}.myqcloud.com/{bucket}
+//	service:     service.cos.myqcloud.com 或 cos.{region}.myqcloud.com
+//	batch:       {uin}.cos-control.{region}.myqcloud.com
+//	ci:          {bucket}.ci.{region}.myqcloud.com
+//	fetch:       {region}.migration.myqcloud.com
+//	metainsight: {appid}.ci.{region}.myqcloud.com
+//	vector:      vectors.{region}.coslake.com
+type DefaultEndpointResolver struct{}
+
+func (DefaultEndpointResolver) ResolveEndpoint(kind EndpointKind, opt *EndpointOptions) (*Endpoint, error) {
+	if opt == nil {
+		opt = &EndpointOptions{}
+	}
+	scheme := opt.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+	if kind == EndpointBucket && opt.CustomDomain != "" {
+		domain := opt.CustomDomain
+		if !strings.Contains(domain, "://") {
+			domain = scheme + "://" + domain
+		}
+		u, err := url.Parse(strings.TrimRight(domain, "/"))
+		if err != nil {
+			return nil, err
+		}
+		if opt.PathStyle {
+			if opt.Bucket == "" {
+				return nil, fmt.Errorf("bucketName[] is invalid: %w", ErrEndpointNotSupported)
+			}
+			u.Path = "/" + opt.Bucket
+		}
+		return &Endpoint{URL: u}, nil
+	}
+	// 缺少必需参数时视为不支持，格式不合法时返回错误
+	needRegion := !(kind == EndpointService || (kind == EndpointBucket && (opt.Accelerate || opt.CDN)))
+	if needRegion && opt.Region == "" {
+		return nil, fmt.Errorf("region[] is invalid: %w", ErrEndpointNotSupported)
+	}
+	if opt.Region != "" && !regionChecker.MatchString(opt.Region) {
+		return nil, fmt.Errorf("region[%v] is invalid", opt.Region)
+	}
+	if kind == EndpointBucket || kind == EndpointCI {
+		if opt.Bucket == "" {
+			return nil, fmt.Errorf("bucketName[] is invalid: %w", ErrEndpointNotSupported)
+		}
+		if !strings.ContainsAny(opt.Bucket, "-") {
+			return nil, fmt.Errorf("bucketName[%v] is invalid", opt.Bucket)
+		}
+	}
+
+	var host, path string
+	switch kind {
+	case EndpointBucket:
+		switch {
+		case opt.PathStyle:
+			host = fmt.Sprintf("cos.%s.myqcloud.com", opt.Region)
+			path = "/" + opt.Bucket
+		case opt.CDN:
+			host = opt.Bucket + ".file.myqcloud.com"
+		case opt.Website:
+			host = fmt.Sprintf("%s.cos-website.%s.myqcloud.com", opt.Bucket, opt.Region)
+		case opt.Accelerate:
+			host = opt.Bucket + ".cos.accelerate.myqcloud.com"
+		case opt.Internal:
+			host = fmt.Sprintf("%s.cos-internal.%s.tencentcos.cn", opt.Bucket, opt.Region)
+		case opt.DualStack:
+			host = fmt.Sprintf("%s.cos.dualstack.%s.myqcloud.com", opt.Bucket, opt.Region)
+		default:
+			host = fmt.Sprintf("%s.cos.%s.myqcloud.com", opt.Bucket, opt.Region)
+		}
+	case EndpointService:
+		host = "service.cos.myqcloud.com"
+		if opt.Region != "" {
+			host = fmt.Sprintf("cos.%s.myqcloud.com", opt.Region)
+		}
+	case EndpointBatch:
+		if opt.UIN == "" {
+			return nil, fmt.Errorf("uin is required for batch endpoint: %w", ErrEndpointNotSupported)
+		}
+		host = fmt.Sprintf("%s.cos-control.%s.myqcloud.com", opt.UIN, opt.Region)
+	case EndpointCI:
+		host = fmt.Sprintf("%s.ci.%s.myqcloud.com", opt.Bucket, opt.Region)
+	case EndpointFetch:
+		host = opt.Region + ".migration.myqcloud.com"
+	case EndpointMetaInsight:
+		appid := opt.AppId
+		if appid == "" {
+			if idx := strings.LastIndex(opt.Bucket, "-"); idx >= 0 {
+				appid = opt.Bucket[idx+1:]
+			}
+		}
+		if appid == "" {
+			return nil, fmt.Errorf("appid is required for metainsight endpoint: %w", ErrEndpointNotSupported)
+		}
+		host = fmt.Sprintf("%s.ci.%s.myqcloud.com", appid, opt.Region)
+	case EndpointVector:
+		host = fmt.Sprintf("vectors.%s.coslake.com", opt.Region)
+		if opt.Internal {
+			host = fmt.Sprintf("vectors.%s.internal.tencentcos.com", opt.Region)
+		}
+	default:
+		return nil, ErrEndpointNotSupported
+	}
+	u, err := url.Parse(scheme + "://" + host + path)
+	if err != nil {
+		return nil, err
+	}
+	ep := &Endpoint{URL: u}
+	if kind == EndpointBucket && path == "" {
+		if fallback := toSwitchHost(u); fallback != u {
+			ep.Fallback = fallback
+		}
+	}
+	return ep, nil
+}
+
+// SetEndpointResolver 使用 r 解析所有服务的地址并写入 BaseURL，之后发往这些地址的请求
+// 使用解析出的签名域名，且不再做域名格式校验。
+// r 返回 ErrEndpointNotSupported（例如缺少该服务所需的参数）的服务保留原有地址。
+// 与直接修改 BaseURL 相同，SetEndpointResolver 没有加锁，需要在 Client 被并发使用之前调用。
+func (c *Client) SetEndpointResolver(r EndpointResolver, opt *EndpointOptions) error {
+	endpoints := make(map[EndpointKind]*Endpoint)
+	for _, kind := range endpointKinds {
+		ep, err := r.ResolveEndpoint(kind, opt)
+		if errors.Is(err, ErrEndpointNotSupported) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("resolve %v endpoint failed: %w", kind, err)
+		}
+		if ep != nil && ep.URL != nil {
+			u := *ep.URL
+			endpoints[kind] = &Endpoint{URL: &u, SigningHost: ep.SigningHost, Fallback: ep.Fallback}
+		}
+	}
+	// 解析出的地址不做校验，只校验保留的原有地址
+	unresolved := *c.BaseURL
+	for kind, ep := range endpoints {
+		c.BaseURL.setEndpoint(kind, ep.URL)
+		unresolved.setEndpoint(kind, nil)
+	}
+	c.endpoints = endpoints
+	c.invalidURL = !unresolved.Check()
+	return nil
+}
+
+func (u *BaseURL) setEndpoint(kind EndpointKind, v *url.URL) {
+	switch kind {
+	case EndpointBucket:
+		u.BucketURL = v
+	case EndpointService:
+		u.ServiceURL = v
+	case EndpointBatch:
+		u.BatchURL = v
+	case EndpointCI:
+		u.CIURL = v
+	case EndpointFetch:
+		u.FetchURL = v
+	case EndpointMetaInsight:
+		u.MetaInsightURL = v
+	case EndpointVector:
+		u.VectorURL = v
+	}
+}
+
+// resolvedEndpoint 返回 baseURL 对应的已解析地址，按 scheme、域名和路径匹配 URL 或 Fallback，
+// 调用方修改或替换了 BaseURL 中的地址后不再匹配
+func (c *Client) resolvedEndpoint(baseURL *url.URL) *Endpoint {
+	if baseURL == nil {
+		return nil
+	}
+	for _, kind := range endpointKinds {
+		if ep := c.endpoints[kind]; ep != nil && (sameEndpointURL(ep.URL, baseURL) || sameEndpointURL(ep.Fallback, baseURL)) {
+			return ep
+		}
+	}
+	return nil
+}
+
+func sameEndpointURL(a, b *url.URL) bool {
+	return a != nil && b != nil && a.Scheme == b.Scheme && a.Host == b.Host &&
+		strings.TrimRight(a.Path, "/") == strings.TrimRight(b.Path, "/")
+}
+
+// switchHost 返回 AutoSwitchHost 重试使用的地址，优先使用解析器提供的备用地址
+func (c *Client) switchHost(u *url.URL) *url.URL {
+	if ep := c.resolvedEndpoint(u); ep != nil {
+		if ep.Fallback != nil {
+			return ep.Fallback
+		}
+		return u
+	}
+	return toSwitchHost(u)
+}
+
+// signingHost 返回 baseURL 对应的签名域名，未设置时返回空
+func (c *Client) signingHost(baseURL *url.URL) string {
+	if ep := c.resolvedEndpoint(baseURL); ep != nil && sameEndpointURL(ep.URL, baseURL) {
+		return ep.SigningHost
+	}
+	return ""
+}
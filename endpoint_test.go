@@ -0,0 +1,136 @@
+package cos
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestDefaultEndpointResolver(t *testing.T) {
+	r := DefaultEndpointResolver{}
+	cases := []struct {
+		kind EndpointKind
+		opt  EndpointOptions
+		want string
+	}{
+		{EndpointBucket, EndpointOptions{Bucket: "test-125", Region: "ap-guangzhou"}, "https://test-125.cos.ap-guangzhou.myqcloud.com"},
+		{EndpointBucket, EndpointOptions{Bucket: "test-125", Region: "ap-guangzhou", Scheme: "http", Internal: true}, "http://test-125.cos-internal.ap-guangzhou.tencentcos.cn"},
+		{EndpointBucket, EndpointOptions{Bucket: "test-125", Accelerate: true}, "https://test-125.cos.accelerate.myqcloud.com"},
+		{EndpointBucket, EndpointOptions{Bucket: "test-125", Region: "ap-guangzhou", DualStack: true}, "https://test-125.cos.dualstack.ap-guangzhou.myqcloud.com"},
+		{EndpointBucket, EndpointOptions{Bucket: "test-125", Region: "ap-guangzhou", Website: true}, "https://test-125.cos-website.ap-guangzhou.myqcloud.com"},
+		{EndpointBucket, EndpointOptions{Bucket: "test-125", CDN: true}, "https://test-125.file.myqcloud.com"},
+		{EndpointBucket, EndpointOptions{Bucket: "test-125", CustomDomain: "static.example.com"}, "https://static.example.com"},
+		{EndpointService, EndpointOptions{}, "https://service.cos.myqcloud.com"},
+		{EndpointService, EndpointOptions{Region: "ap-guangzhou"}, "https://cos.ap-guangzhou.myqcloud.com"},
+		{EndpointBatch, EndpointOptions{UIN: "100", Region: "ap-guangzhou"}, "https://100.cos-control.ap-guangzhou.myqcloud.com"},
+		{EndpointCI, EndpointOptions{Bucket: "test-125", Region: "ap-guangzhou"}, "https://test-125.ci.ap-guangzhou.myqcloud.com"},
+		{EndpointFetch, EndpointOptions{Region: "ap-guangzhou"}, "https://ap-guangzhou.migration.myqcloud.com"},
+		{EndpointMetaInsight, EndpointOptions{Bucket: "test-125", Region: "ap-guangzhou"}, "https://125.ci.ap-guangzhou.myqcloud.com"},
+		{EndpointVector, EndpointOptions{Region: "ap-guangzhou", Internal: true}, "https://vectors.ap-guangzhou.internal.tencentcos.com"},
+	}
+	for _, c := range cases {
+		ep, err := r.ResolveEndpoint(c.kind, &c.opt)
+		if err != nil {
+			t.Errorf("ResolveEndpoint(%v, %+v) returned error: %v", c.kind, c.opt, err)
+			continue
+		}
+		if got := ep.URL.String(); got != c.want {
+			t.Errorf("ResolveEndpoint(%v, %+v) = %v, want %v", c.kind, c.opt, got, c.want)
+		}
+	}
+
+	ep, _ := r.ResolveEndpoint(EndpointBucket, &EndpointOptions{Bucket: "test-125", Region: "ap-guangzhou"})
+	if ep.Fallback == nil || ep.Fallback.Host != "test-125.cos.ap-guangzhou.tencentcos.cn" {
+		t.Errorf("ResolveEndpoint fallback: %v", ep.Fallback)
+	}
+	if _, err := r.ResolveEndpoint(EndpointBatch, &EndpointOptions{Region: "ap-guangzhou"}); !errors.Is(err, ErrEndpointNotSupported) {
+		t.Errorf("ResolveEndpoint without uin returned error: %v", err)
+	}
+	if _, err := r.ResolveEndpoint(EndpointBucket, &EndpointOptions{Bucket: "test-125", Region: "ap_guangzhou"}); err == nil || errors.Is(err, ErrEndpointNotSupported) {
+		t.Errorf("ResolveEndpoint with invalid region returned error: %v", err)
+	}
+}
+
+func TestClient_SetEndpointResolver(t *testing.T) {
+	var hosts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts = append(hosts, r.Host)
+		if !strings.Contains(r.Header.Get("Authorization"), "host") {
+			t.Errorf("Authorization does not sign host: %v", r.Header.Get("Authorization"))
+		}
+	}))
+	defer server.Close()
+	private, _ := url.Parse(server.URL)
+
+	// 默认的域名校验会拒绝不带 appid 的存储桶域名
+	bad, _ := url.Parse("http://test.cos.ap-guangzhou.myqcloud.com")
+	c := NewClient(&BaseURL{BucketURL: bad}, &http.Client{
+		Transport: &AuthorizationTransport{SecretID: "ak", SecretKey: "sk"},
+	})
+	if _, err := c.Object.Head(context.Background(), "a", nil); err == nil || err.Error() != invalidBucketErr.Error() {
+		t.Fatalf("Object.Head with invalid BucketURL returned error: %v", err)
+	}
+
+	err := c.SetEndpointResolver(EndpointResolverFunc(func(kind EndpointKind, opt *EndpointOptions) (*Endpoint, error) {
+		switch kind {
+		case EndpointBucket, EndpointCI:
+			ep, err := DefaultEndpointResolver{}.ResolveEndpoint(kind, opt)
+			if err != nil {
+				return nil, err
+			}
+			return &Endpoint{URL: private, SigningHost: ep.URL.Host}, nil
+		}
+		return nil, ErrEndpointNotSupported
+	}), &EndpointOptions{Bucket: "test-125", Region: "ap-guangzhou"})
+	if err != nil {
+		t.Fatalf("SetEndpointResolver returned error: %v", err)
+	}
+	if c.BaseURL.BucketURL.Host != private.Host || c.BaseURL.ServiceURL.Host != "service.cos.myqcloud.com" {
+		t.Errorf("SetEndpointResolver BaseURL: %+v", c.BaseURL)
+	}
+	if _, err = c.Object.Head(context.Background(), "a", nil); err != nil {
+		t.Fatalf("Object.Head returned error: %v", err)
+	}
+	if len(hosts) == 0 || hosts[0] != "test-125.cos.ap-guangzhou.myqcloud.com" {
+		t.Errorf("request Host: %v", hosts)
+	}
+
+	u, err := c.Object.GetPresignedURL(context.Background(), http.MethodGet, "a", "ak", "sk", 0, nil)
+	if err != nil || u.Host != private.Host {
+		t.Errorf("GetPresignedURL returned %v, error: %v", u, err)
+	}
+
+	// 替换为相同地址的新 URL 时仍使用签名域名
+	replaced := *c.BaseURL.BucketURL
+	c.BaseURL.BucketURL = &replaced
+	hosts = nil
+	if _, err = c.Object.Head(context.Background(), "a", nil); err != nil || len(hosts) != 1 || hosts[0] != "test-125.cos.ap-guangzhou.myqcloud.com" {
+		t.Errorf("Object.Head with replaced BucketURL sent Host %v, error: %v", hosts, err)
+	}
+	// 原地修改域名后不再使用原有的签名域名
+	c.BaseURL.BucketURL.Host = strings.Replace(private.Host, "127.0.0.1", "localhost", 1)
+	hosts = nil
+	if _, err = c.Object.Head(context.Background(), "a", nil); err != nil || len(hosts) != 1 || hosts[0] != c.BaseURL.BucketURL.Host {
+		t.Errorf("Object.Head with modified BucketURL sent Host %v, error: %v", hosts, err)
+	}
+
+	if err = c.SetEndpointResolver(DefaultEndpointResolver{}, &EndpointOptions{Bucket: "test", Region: "ap-guangzhou"}); err == nil {
+		t.Errorf("SetEndpointResolver with invalid bucket expect error")
+	}
+}
+
+func TestClient_SwitchHostWithResolver(t *testing.T) {
+	c := NewClient(nil, nil)
+	c.SetEndpointResolver(DefaultEndpointResolver{}, &EndpointOptions{Bucket: "test-125", Region: "ap-guangzhou"})
+	if got := c.switchHost(c.BaseURL.BucketURL); got.Host != "test-125.cos.ap-guangzhou.tencentcos.cn" {
+		t.Errorf("switchHost returned %v", got)
+	}
+	c.SetEndpointResolver(DefaultEndpointResolver{}, &EndpointOptions{Bucket: "test-125", CustomDomain: "static.example.com"})
+	if got := c.switchHost(c.BaseURL.BucketURL); got != c.BaseURL.BucketURL {
+		t.Errorf("switchHost for custom domain returned %v", got)
+	}
+}
@@ -0,0 +1,170 @@
+package cos
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+)
+
+// ErrorCode 是 COS 返回的错误码，同时实现了 error 接口，可以作为 errors.Is 的 target：
+//
+//	if errors.Is(err, cos.ErrNoSuchKey) { ... }
+//
+// https://cloud.tencent.com/document/product/436/7730
+type ErrorCode string
+
+func (c ErrorCode) Error() string {
+	return string(c)
+}
+
+// COS 常见错误码
+const (
+	ErrNoSuchKey                ErrorCode = "NoSuchKey"
+	ErrNoSuchBucket             ErrorCode = "NoSuchBucket"
+	ErrNoSuchUpload             ErrorCode = "NoSuchUpload"
+	ErrNoSuchVersion            ErrorCode = "NoSuchVersion"
+	ErrAccessDenied             ErrorCode = "AccessDenied"
+	ErrSignatureDoesNotMatch    ErrorCode = "SignatureDoesNotMatch"
+	ErrInvalidAccessKeyID       ErrorCode = "InvalidAccessKeyId"
+	ErrExpiredToken             ErrorCode = "ExpiredToken"
+	ErrInvalidToken             ErrorCode = "InvalidToken"
+	ErrRequestTimeTooSkewed     ErrorCode = "RequestTimeTooSkewed"
+	ErrPreconditionFailed       ErrorCode = "PreconditionFailed"
+	ErrSlowDown                 ErrorCode = "SlowDown"
+	ErrRequestTimeout           ErrorCode = "RequestTimeout"
+	ErrInternalError            ErrorCode = "InternalError"
+	ErrServiceUnavailable       ErrorCode = "ServiceUnavailable"
+	ErrInvalidDigest            ErrorCode = "InvalidDigest"
+	ErrBadDigest                ErrorCode = "BadDigest"
+	ErrInvalidArgument          ErrorCode = "InvalidArgument"
+	ErrInvalidRequest           ErrorCode = "InvalidRequest"
+	ErrInvalidPart              ErrorCode = "InvalidPart"
+	ErrInvalidPartOrder         ErrorCode = "InvalidPartOrder"
+	ErrEntityTooSmall           ErrorCode = "EntityTooSmall"
+	ErrEntityTooLarge           ErrorCode = "EntityTooLarge"
+	ErrKeyTooLong               ErrorCode = "KeyTooLong"
+	ErrMethodNotAllowed         ErrorCode = "MethodNotAllowed"
+	ErrInvalidObjectState       ErrorCode = "InvalidObjectState"
+	ErrRestoreAlreadyInProgress ErrorCode = "RestoreAlreadyInProgress"
+	ErrBucketAlreadyExists      ErrorCode = "BucketAlreadyExists"
+	ErrBucketAlreadyOwnedByYou  ErrorCode = "BucketAlreadyOwnedByYou"
+	ErrBucketNotEmpty           ErrorCode = "BucketNotEmpty"
+	ErrObjectNotAppendable      ErrorCode = "ObjectNotAppendable"
+	ErrPositionNotEqualToLength ErrorCode = "PositionNotEqualToLength"
+)
+
+// Vector 服务错误码，见 VectorErrorResponse
+const (
+	ErrVectorValidation         ErrorCode = "ValidationException"
+	ErrVectorQuotaExceeded      ErrorCode = "ServiceQuotaExceededException"
+	ErrVectorAccessDenied       ErrorCode = "AccessDeniedException"
+	ErrVectorNotFound           ErrorCode = "NotFoundException"
+	ErrVectorConflict           ErrorCode = "ConflictException"
+	ErrVectorTooManyRequests    ErrorCode = "TooManyRequestsException"
+	ErrVectorInternal           ErrorCode = "InternalServerException"
+	ErrVectorServiceUnavailable ErrorCode = "ServiceUnavailableException"
+)
+
+// errorCode 返回响应的错误码，HEAD 请求等没有响应 body 时使用 X-Cos-Error-Code 头部
+func errorCode(code string, resp *http.Response) ErrorCode {
+	if code == "" && resp != nil {
+		code = resp.Header.Get("X-Cos-Error-Code")
+	}
+	return ErrorCode(code)
+}
+
+// ErrorCode 返回错误码，Code 为空时使用 X-Cos-Error-Code 头部
+func (r *ErrorResponse) ErrorCode() ErrorCode {
+	return errorCode(r.Code, r.Response)
+}
+
+// Is 使 errors.Is 可以按错误码匹配 ErrorCode
+func (r *ErrorResponse) Is(target error) bool {
+	code, ok := target.(ErrorCode)
+	return ok && code != "" && r.ErrorCode() == code
+}
+
+// ErrorCode 返回 Vector 服务的错误码
+func (r *VectorErrorResponse) ErrorCode() ErrorCode {
+	return errorCode(r.Code, r.Response)
+}
+
+// Is 使 errors.Is 可以按错误码匹配 ErrorCode
+func (r *VectorErrorResponse) Is(target error) bool {
+	code, ok := target.(ErrorCode)
+	return ok && code != "" && r.ErrorCode() == code
+}
+
+// serviceError 从 err 中提取服务端返回的状态码和错误码，err 不是服务端返回的错误时 ok 为 false
+func serviceError(err error) (status int, code ErrorCode, ok bool) {
+	var cosErr *ErrorResponse
+	if errors.As(err, &cosErr) {
+		if cosErr.Response != nil {
+			status = cosErr.Response.StatusCode
+		}
+		return status, cosErr.ErrorCode(), true
+	}
+	var vecErr *VectorErrorResponse
+	if errors.As(err, &vecErr) {
+		if vecErr.Response != nil {
+			status = vecErr.Response.StatusCode
+		}
+		return status, vecErr.ErrorCode(), true
+	}
+	return 0, "", false
+}
+
+// IsThrottledError 判断是否为请求频率超过限制导致的错误
+func IsThrottledError(err error) bool {
+	status, code, ok := serviceError(err)
+	if !ok {
+		return false
+	}
+	switch code {
+	case ErrSlowDown, ErrVectorTooManyRequests:
+		return true
+	}
+	return status == http.StatusTooManyRequests
+}
+
+// IsAuthError 判断是否为鉴权失败导致的错误，例如密钥错误、临时密钥过期、签名时间偏差过大或没有权限
+func IsAuthError(err error) bool {
+	status, code, ok := serviceError(err)
+	if !ok {
+		return false
+	}
+	switch code {
+	case ErrAccessDenied, ErrSignatureDoesNotMatch, ErrInvalidAccessKeyID, ErrExpiredToken,
+		ErrInvalidToken, ErrRequestTimeTooSkewed, ErrVectorAccessDenied:
+		return true
+	}
+	return status == http.StatusUnauthorized || status == http.StatusForbidden
+}
+
+// IsClientError 判断是否为请求本身导致的错误（4xx），频率限制和请求超时除外，这类错误重试不会成功
+func IsClientError(err error) bool {
+	status, code, ok := serviceError(err)
+	if !ok || code == ErrRequestTimeout || IsThrottledError(err) {
+		return false
+	}
+	return status >= 400 && status < 500
+}
+
+// IsRetryableError 判断错误是否可以重试：网络错误、服务端错误（5xx）、频率限制和请求超时。
+// context 被取消或超时、参数校验失败等本地错误不可重试
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	status, code, ok := serviceError(err)
+	if !ok {
+		var nerr net.Error
+		return errors.As(err, &nerr) || errors.Is(err, io.ErrUnexpectedEOF)
+	}
+	if IsThrottledError(err) || code == ErrRequestTimeout {
+		return true
+	}
+	return status >= 500
+}
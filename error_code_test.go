@@ -0,0 +1,91 @@
+package cos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestErrorCode_Is(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.Header().Set("X-Cos-Error-Code", "PreconditionFailed")
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+	})
+
+	_, err := client.Object.Get(context.Background(), "test", nil)
+	if !errors.Is(err, ErrNoSuchKey) || errors.Is(err, ErrNoSuchBucket) {
+		t.Errorf("Object.Get returned error: %v", err)
+	}
+	var cosErr *ErrorResponse
+	if !errors.As(err, &cosErr) || cosErr.ErrorCode() != ErrNoSuchKey {
+		t.Errorf("errors.As returned %v", cosErr)
+	}
+	// HEAD 请求没有响应 body，使用 X-Cos-Error-Code 头部
+	_, err = client.Object.Head(context.Background(), "test", nil)
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("Object.Head returned error: %v", err)
+	}
+
+	r := &RetryError{}
+	r.Add(makeCOSErr("InternalError", 500))
+	r.Add(fmt.Errorf("wrapped: %w", makeCOSErr("SlowDown", 503)))
+	if !errors.Is(r, ErrSlowDown) || errors.Is(r, ErrInternalError) {
+		t.Errorf("RetryError matched wrong code: %v", r)
+	}
+	if !errors.Is(makeVectorErr("NotFoundException", 404), ErrVectorNotFound) {
+		t.Errorf("VectorErrorResponse expect to match ErrVectorNotFound")
+	}
+	if errors.Is(makeCOSErr("", 404), ErrorCode("")) {
+		t.Errorf("empty code expect not to match")
+	}
+}
+
+func TestErrorClassification(t *testing.T) {
+	netErr := &url.Error{Op: "Get", URL: "http://example.com", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}
+	tests := []struct {
+		name                               string
+		err                                error
+		retryable, throttled, auth, client bool
+	}{
+		{"nil", nil, false, false, false, false},
+		{"local", errors.New("invalid bucket"), false, false, false, false},
+		{"canceled", fmt.Errorf("get: %w", context.Canceled), false, false, false, false},
+		{"network", netErr, true, false, false, false},
+		{"NoSuchKey", makeCOSErr("NoSuchKey", 404), false, false, false, true},
+		{"AccessDenied", makeCOSErr("AccessDenied", 403), false, false, true, true},
+		{"bare 403", makeCOSErr("", 403), false, false, true, true},
+		{"SignatureDoesNotMatch", makeCOSErr("SignatureDoesNotMatch", 403), false, false, true, true},
+		{"RequestTimeout", makeCOSErr("RequestTimeout", 400), true, false, false, false},
+		{"SlowDown", makeCOSErr("SlowDown", 503), true, true, false, false},
+		{"InternalError", makeCOSErr("InternalError", 500), true, false, false, false},
+		{"vector 429", makeVectorErr("TooManyRequestsException", 429), true, true, false, false},
+		{"vector validation", makeVectorErr("ValidationException", 400), false, false, false, true},
+		{"retry", &RetryError{Errs: []error{netErr, makeCOSErr("SlowDown", 503)}}, true, true, false, false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryableError(tt.err); got != tt.retryable {
+			t.Errorf("%v: IsRetryableError returned %v", tt.name, got)
+		}
+		if got := IsThrottledError(tt.err); got != tt.throttled {
+			t.Errorf("%v: IsThrottledError returned %v", tt.name, got)
+		}
+		if got := IsAuthError(tt.err); got != tt.auth {
+			t.Errorf("%v: IsAuthError returned %v", tt.name, got)
+		}
+		if got := IsClientError(tt.err); got != tt.client {
+			t.Errorf("%v: IsClientError returned %v", tt.name, got)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package cos
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHedgeMinSamples = 20
+	hedgeWindowSize        = 256
+)
+
+// HedgePolicy 是 Bucket/Object 的 GET 和 HEAD 请求（Object.Get、Head、Download 的分块、Bucket.Get）的对冲策略：
+// 第一个请求在延迟内没有返回响应头部时再发送一个相同的请求，先返回的响应生效，另一个请求被取消
+type HedgePolicy struct {
+	// 固定的对冲延迟，设置 Percentile 且样本不足时也使用该值，为 0 时样本不足前不对冲
+	Delay time.Duration
+	// 按最近请求返回响应头部耗时的分位数计算延迟，例如 0.95，为 0 时只使用 Delay
+	Percentile float64
+	// 按分位数计算延迟所需的最少样本数，默认为 20
+	MinSamples int
+	// 分位数延迟的上下限，为 0 时不限制
+	MinDelay time.Duration
+	MaxDelay time.Duration
+	// 对冲请求发往备用域名，例如 {bucket}.cos.{region}.tencentcos.cn
+	SwitchHost bool
+
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+	hedged  int64
+	won     int64
+}
+
+// Stats 返回发送的对冲请求数和其中先于第一个请求返回的次数
+func (p *HedgePolicy) Stats() (hedged, won int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.hedged, p.won
+}
+
+func (p *HedgePolicy) count(hedged, won int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.hedged += hedged
+	p.won += won
+}
+
+func (p *HedgePolicy) observe(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.samples) < hedgeWindowSize {
+		p.samples = append(p.samples, d)
+		return
+	}
+	p.samples[p.next] = d
+	p.next = (p.next + 1) % hedgeWindowSize
+}
+
+// delay 返回发送对冲请求前等待的时间，返回 false 时不发送对冲请求
+func (p *HedgePolicy) delay() (time.Duration, bool) {
+	if p.Percentile <= 0 || p.Percentile > 1 {
+		return p.Delay, p.Delay > 0
+	}
+	minSamples := p.MinSamples
+	if minSamples <= 0 {
+		minSamples = defaultHedgeMinSamples
+	}
+	p.mu.Lock()
+	if len(p.samples) < minSamples {
+		p.mu.Unlock()
+		return p.Delay, p.Delay > 0
+	}
+	samples := append([]time.Duration(nil), p.samples...)
+	p.mu.Unlock()
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	idx := int(float64(len(samples))*p.Percentile+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(samples) {
+		idx = len(samples) - 1
+	}
+	d := samples[idx]
+	if p.MinDelay > 0 && d < p.MinDelay {
+		d = p.MinDelay
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d, true
+}
+
+// hedgeable 判断请求是否可以对冲：幂等的读请求，且 result 可以为每个请求单独创建
+func (c *Client) hedgeable(opt *sendOptions) bool {
+	if c.Conf.Hedge == nil || (opt.method != http.MethodGet && opt.method != http.MethodHead) {
+		return false
+	}
+	if opt.body != nil || opt.baseURL == nil || opt.baseURL != c.BaseURL.BucketURL {
+		return false
+	}
+	if opt.result == nil {
+		return true
+	}
+	if _, ok := opt.result.(io.Writer); ok {
+		return false
+	}
+	return reflect.TypeOf(opt.result).Kind() == reflect.Ptr
+}
+
+// responseHeaderKey 对应的 func() 在 doAPI 收到响应头部时调用
+type responseHeaderKey struct{}
+
+type hedgeAttempt struct {
+	resp   *Response
+	err    error
+	result interface{}
+	idx    int
+}
+
+// sendHedged 发送请求，第一个请求在 Hedge 的延迟内没有返回时发送对冲请求
+func (c *Client) sendHedged(ctx context.Context, opt *sendOptions) (*Response, error) {
+	policy := c.Conf.Hedge
+	ch := make(chan *hedgeAttempt, 2)
+	var cancels []context.CancelFunc
+	launch := func(baseURL *url.URL) {
+		actx, cancel := context.WithCancel(ctx)
+		idx := len(cancels)
+		cancels = append(cancels, cancel)
+		o := *opt
+		o.baseURL = baseURL
+		if opt.result != nil {
+			o.result = reflect.New(reflect.TypeOf(opt.result).Elem()).Interface()
+		}
+		go func() {
+			// 只记录返回响应头部的耗时，不包括读取和解析 body
+			var header time.Time
+			hctx := context.WithValue(actx, responseHeaderKey{}, func() { header = time.Now() })
+			start := time.Now()
+			resp, err := c.send(hctx, &o)
+			if err == nil && !header.IsZero() {
+				policy.observe(header.Sub(start))
+			}
+			ch <- &hedgeAttempt{resp: resp, err: err, result: o.result, idx: idx}
+		}()
+	}
+
+	launch(opt.baseURL)
+	inflight := 1
+	// 不对冲时仍然记录耗时，用于计算分位数
+	var timerC <-chan time.Time
+	if d, ok := policy.delay(); ok {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timerC = timer.C
+	}
+	for {
+		select {
+		case <-timerC:
+			inflight++
+			policy.count(1, 0)
+			baseURL := opt.baseURL
+			if policy.SwitchHost {
+				baseURL = c.switchHost(baseURL)
+			}
+			launch(baseURL)
+		case a := <-ch:
+			inflight--
+			// 网络错误时等待另一个请求，服务端返回的错误同样视为有效响应
+			if a.resp == nil && a.err != nil && inflight > 0 {
+				cancels[a.idx]()
+				continue
+			}
+			// 取消落败的请求，并在其返回后关闭 body
+			for i, cancel := range cancels {
+				if i != a.idx {
+					cancel()
+				}
+			}
+			if inflight > 0 {
+				go discardHedgeAttempts(ch, inflight)
+			}
+			if a.idx > 0 {
+				policy.count(0, 1)
+			}
+			if opt.result != nil && a.err == nil {
+				reflect.ValueOf(opt.result).Elem().Set(reflect.ValueOf(a.result).Elem())
+			}
+			// 流式读取 body 时，在 body 关闭后再释放胜出请求的 context
+			if opt.disableCloseBody && a.err == nil && a.resp != nil && a.resp.Body != nil {
+				a.resp.Body = &cancelOnClose{ReadCloser: a.resp.Body, cancel: cancels[a.idx]}
+			} else {
+				cancels[a.idx]()
+			}
+			return a.resp, a.err
+		}
+	}
+}
+
+// discardHedgeAttempts 回收落败的请求
+func discardHedgeAttempts(ch <-chan *hedgeAttempt, n int) {
+	for i := 0; i < n; i++ {
+		if a := <-ch; a.resp != nil && a.resp.Body != nil {
+			a.resp.Body.Close()
+		}
+	}
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.cancel()
+	return err
+}
@@ -0,0 +1,165 @@
+package cos
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedgePolicy_Delay(t *testing.T) {
+	p := &HedgePolicy{Percentile: 0.9, MinSamples: 10, MaxDelay: 80 * time.Millisecond}
+	if _, ok := p.delay(); ok {
+		t.Errorf("delay without samples expect no hedging")
+	}
+	for i := 1; i <= 10; i++ {
+		p.observe(time.Duration(i) * 10 * time.Millisecond)
+	}
+	if d, ok := p.delay(); !ok || d != 80*time.Millisecond {
+		t.Errorf("delay returned %v, %v, want 80ms", d, ok)
+	}
+	p.MaxDelay = 0
+	if d, _ := p.delay(); d != 90*time.Millisecond {
+		t.Errorf("delay returned %v, want 90ms", d)
+	}
+	if d, ok := (&HedgePolicy{Delay: time.Second}).delay(); !ok || d != time.Second {
+		t.Errorf("delay returned %v, %v, want 1s", d, ok)
+	}
+}
+
+func TestObjectService_GetHedged(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var requests int32
+	cancelled := make(chan struct{}, 1)
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+				cancelled <- struct{}{}
+				return
+			case <-time.After(2 * time.Second):
+			}
+		}
+		fmt.Fprint(w, "hedged")
+	})
+	client.Conf.Hedge = &HedgePolicy{Delay: 20 * time.Millisecond}
+
+	start := time.Now()
+	resp, err := client.Object.Get(context.Background(), "test", nil)
+	if err != nil {
+		t.Fatalf("Object.Get returned error: %v", err)
+	}
+	b, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(b) != "hedged" || time.Since(start) > time.Second {
+		t.Errorf("Object.Get returned %q after %v", b, time.Since(start))
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Errorf("slow request was not cancelled")
+	}
+	if hedged, won := client.Conf.Hedge.Stats(); hedged != 1 || won != 1 {
+		t.Errorf("Stats returned %v, %v, want 1, 1", hedged, won)
+	}
+
+	// 第一个请求及时返回时不发送对冲请求
+	atomic.StoreInt32(&requests, 1)
+	if _, err = client.Object.Head(context.Background(), "test", nil); err != nil {
+		t.Fatalf("Object.Head returned error: %v", err)
+	}
+	if hedged, _ := client.Conf.Hedge.Stats(); hedged != 1 || atomic.LoadInt32(&requests) != 2 {
+		t.Errorf("Object.Head sent %v requests, hedged %v", atomic.LoadInt32(&requests), hedged)
+	}
+}
+
+func TestBucketService_GetHedged(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var requests int32
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		if n == 1 {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(2 * time.Second):
+			}
+		}
+		fmt.Fprintf(w, `<ListBucketResult><Name>test-125</Name><Contents><Key>key%d</Key></Contents></ListBucketResult>`, n)
+	})
+	client.Conf.Hedge = &HedgePolicy{Delay: 20 * time.Millisecond}
+
+	res, _, err := client.Bucket.Get(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Bucket.Get returned error: %v", err)
+	}
+	if res.Name != "test-125" || len(res.Contents) != 1 || res.Contents[0].Key != "key2" {
+		t.Errorf("Bucket.Get returned %+v", res)
+	}
+}
+
+func TestHedgePolicy_ObserveHeaderTime(t *testing.T) {
+	setup()
+	defer teardown()
+
+	// 响应头部立即返回，body 延迟写入
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<ListBucketResult><Name>test-125</Name>`)
+		w.(http.Flusher).Flush()
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprint(w, `</ListBucketResult>`)
+	})
+	policy := &HedgePolicy{Percentile: 0.9, MinSamples: 1}
+	client.Conf.Hedge = policy
+
+	if _, _, err := client.Bucket.Get(context.Background(), nil); err != nil {
+		t.Fatalf("Bucket.Get returned error: %v", err)
+	}
+	if d, ok := policy.delay(); !ok || d >= 40*time.Millisecond {
+		t.Errorf("HedgePolicy delay returned %v, %v, want header time", d, ok)
+	}
+}
+
+func TestObjectService_GetHedgedSwitchHost(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer primary.Close()
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "fallback")
+	}))
+	defer fallback.Close()
+
+	pu, _ := url.Parse(primary.URL)
+	fu, _ := url.Parse(fallback.URL)
+	c := NewClient(nil, nil)
+	c.SetEndpointResolver(EndpointResolverFunc(func(kind EndpointKind, opt *EndpointOptions) (*Endpoint, error) {
+		if kind != EndpointBucket {
+			return nil, ErrEndpointNotSupported
+		}
+		return &Endpoint{URL: pu, Fallback: fu}, nil
+	}), nil)
+	c.Conf.Hedge = &HedgePolicy{Delay: 20 * time.Millisecond, SwitchHost: true}
+
+	resp, err := c.Object.Get(context.Background(), "test", nil)
+	if err != nil {
+		t.Fatalf("Object.Get returned error: %v", err)
+	}
+	b, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(b) != "fallback" {
+		t.Errorf("Object.Get returned %q, want fallback", b)
+	}
+}
@@ -454,6 +454,100 @@ func GetRange(rangeStr string) (*RangeOptions, error) {
 	return &ropt, nil
 }
 
+// FormatMultiRangeOptions 将多个范围格式化为一个 Range 头部: bytes=M-N,X-Y,-Z
+func FormatMultiRangeOptions(opts []RangeOptions) string {
+	var ranges []string
+	for i := range opts {
+		r := FormatRangeOptions(&opts[i])
+		if r == "" {
+			continue
+		}
+		ranges = append(ranges, strings.TrimPrefix(r, "bytes="))
+	}
+	if len(ranges) == 0 {
+		return ""
+	}
+	return "bytes=" + strings.Join(ranges, ",")
+}
+
+// ParseRanges 解析包含多个范围的 Range 头部，GetRange 只返回第一个范围
+func ParseRanges(rangeStr string) ([]RangeOptions, error) {
+	slices := strings.SplitN(rangeStr, "=", 2)
+	if len(slices) != 2 || strings.TrimSpace(slices[0]) != "bytes" {
+		return nil, fmt.Errorf("Invalid Parameter Range: %v", rangeStr)
+	}
+	var res []RangeOptions
+	for _, rstr := range strings.Split(slices[1], ",") {
+		ropt, err := GetRange("bytes=" + strings.TrimSpace(rstr))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid Parameter Range: %v, err: %v", rangeStr, err)
+		}
+		res = append(res, *ropt)
+	}
+	return res, nil
+}
+
+// resolve 根据对象总长度计算范围对应的实际字节区间 [start, end]
+func (opt *RangeOptions) resolve(total int64) (int64, int64, bool) {
+	var start, end int64
+	switch {
+	case opt.HasStart && opt.HasEnd:
+		start, end = opt.Start, opt.End
+		if total >= 0 && end >= total {
+			end = total - 1
+		}
+	case opt.HasStart:
+		if total < 0 {
+			return 0, 0, false
+		}
+		start, end = opt.Start, total-1
+	case opt.HasEnd:
+		if total < 0 {
+			return 0, 0, false
+		}
+		start, end = total-opt.End, total-1
+		if start < 0 {
+			start = 0
+		}
+	default:
+		return 0, 0, false
+	}
+	if start > end {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// parseContentRange 解析 Content-Range: bytes M-N/Total，Total 为 * 时返回 -1
+func parseContentRange(v string) (start, end, total int64, err error) {
+	v = strings.TrimSpace(v)
+	if !strings.HasPrefix(v, "bytes ") {
+		return 0, 0, 0, fmt.Errorf("Invalid Content-Range: %v", v)
+	}
+	v = strings.TrimSpace(v[len("bytes "):])
+	slash := strings.IndexByte(v, '/')
+	if slash < 0 {
+		return 0, 0, 0, fmt.Errorf("Invalid Content-Range: %v", v)
+	}
+	total = -1
+	if t := v[slash+1:]; t != "*" {
+		if total, err = strconv.ParseInt(t, 10, 64); err != nil {
+			return 0, 0, 0, fmt.Errorf("Invalid Content-Range: %v", v)
+		}
+	}
+	sted := strings.SplitN(v[:slash], "-", 2)
+	if len(sted) != 2 {
+		return 0, 0, 0, fmt.Errorf("Invalid Content-Range: %v", v)
+	}
+	if start, err = strconv.ParseInt(sted[0], 10, 64); err != nil {
+		return 0, 0, 0, fmt.Errorf("Invalid Content-Range: %v", v)
+	}
+	if end, err = strconv.ParseInt(sted[1], 10, 64); err != nil || end < start {
+		return 0, 0, 0, fmt.Errorf("Invalid Content-Range: %v", v)
+	}
+	return start, end, total, nil
+}
+
 var deliverHeader = map[string]bool{}
 
 func isDeliverHeader(key string) bool {
@@ -416,3 +416,51 @@ func TestEncodeUserData_SpecialChars(t *testing.T) {
 		t.Errorf("roundtrip mismatch: in=%v out=%v", in, out)
 	}
 }
+
+func Test_MultiRange(t *testing.T) {
+	opts := []RangeOptions{
+		{HasStart: true, HasEnd: true, Start: 0, End: 9},
+		{},
+		{HasStart: true, Start: 100},
+		{HasEnd: true, End: 8},
+	}
+	rs := FormatMultiRangeOptions(opts)
+	if rs != "bytes=0-9,100-,-8" {
+		t.Errorf("FormatMultiRangeOptions returned %v", rs)
+	}
+	if FormatMultiRangeOptions(nil) != "" {
+		t.Errorf("FormatMultiRangeOptions expect empty")
+	}
+	res, err := ParseRanges("bytes=0-9, 100-,-8")
+	if err != nil {
+		t.Fatalf("ParseRanges returned error: %v", err)
+	}
+	want := []RangeOptions{opts[0], opts[2], opts[3]}
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("ParseRanges returned %+v, want %+v", res, want)
+	}
+	for _, s := range []string{"byte=0-1", "bytes=0-1,a-", "bytes=0-1,"} {
+		if _, err = ParseRanges(s); err == nil {
+			t.Errorf("ParseRanges(%v) expect error", s)
+		}
+	}
+
+	cases := []struct {
+		value             string
+		start, end, total int64
+		hasErr            bool
+	}{
+		{"bytes 0-99/1000", 0, 99, 1000, false},
+		{"bytes 0-99/*", 0, 99, -1, false},
+		{"bytes */1000", 0, 0, 0, true},
+		{"bytes 99-0/1000", 0, 0, 0, true},
+		{"0-99/1000", 0, 0, 0, true},
+		{"bytes 0-99", 0, 0, 0, true},
+	}
+	for _, c := range cases {
+		start, end, total, err := parseContentRange(c.value)
+		if (err != nil) != c.hasErr || (err == nil && (start != c.start || end != c.end || total != c.total)) {
+			t.Errorf("parseContentRange(%v) returned %v-%v/%v, err: %v", c.value, start, end, total, err)
+		}
+	}
+}
@@ -47,6 +47,8 @@ type ObjectGetOptions struct {
 
 	// 下载进度, ProgressCompleteEvent不能表示对应API调用成功，API是否调用成功的判断标准为返回err==nil
 	Listener ProgressListener `header:"-" url:"-" xml:"-"`
+	// 按 Content-Encoding 透明解压客户端压缩上传的对象，并校验原始数据的 CRC64，不支持 Range
+	Decompress bool `header:"-" url:"-" xml:"-"`
 }
 
 // presignedURLTestingOptions is the opt of presigned url
@@ -70,6 +72,13 @@ func (s *ObjectService) Get(ctx context.Context, name string, opt *ObjectGetOpti
 	} else {
 		return nil, errors.New("wrong params")
 	}
+	decompress := opt != nil && opt.Decompress
+	if decompress {
+		if opt.Range != "" {
+			return nil, fmt.Errorf("Decompress doesn't support Range Options")
+		}
+		opt = withIdentityEncoding(opt)
+	}
 
 	sendOpt := sendOptions{
 		baseURL:          s.client.BaseURL.BucketURL,
@@ -88,6 +97,9 @@ func (s *ObjectService) Get(ctx context.Context, name string, opt *ObjectGetOpti
 			}
 		}
 	}
+	if decompress && err == nil && resp != nil {
+		decompressResponse(resp)
+	}
 	return resp, err
 }
 
@@ -99,9 +111,9 @@ func (s *ObjectService) GetToFile(ctx context.Context, name, localpath string, o
 	}
 	defer resp.Body.Close()
 
-	// 使用 teeReader 做流式 CRC64 校验
+	// 使用 teeReader 做流式 CRC64 校验，解压后的数据已由 decompressReader 校验
 	var crcWriter hash.Hash64
-	if s.client.Conf.EnableCRC {
+	if _, ok := resp.Body.(*decompressReader); s.client.Conf.EnableCRC && !ok {
 		if tr, ok := resp.Body.(*teeReader); ok {
 			// Get 已包装了 teeReader（有 Listener），设置 CRC64 writer
 			crcWriter = crc64.New(crc64.MakeTable(crc64.ECMA))
@@ -147,8 +159,9 @@ func (s *ObjectService) GetToFile(ctx context.Context, name, localpath string, o
 }
 
 func (s *ObjectService) GetObjectURL(name string) *url.URL {
-	uri, _ := url.Parse("/" + encodeURIComponent(name, []byte{'/'}))
-	return s.client.BaseURL.BucketURL.ResolveReference(uri)
+	bucketURL := s.client.BaseURL.BucketURL
+	uri, _ := url.Parse(basePath(bucketURL) + "/" + encodeURIComponent(name, []byte{'/'}))
+	return bucketURL.ResolveReference(uri)
 }
 
 type PresignedURLOptions struct {
@@ -207,7 +220,7 @@ func (s *ObjectService) GetPresignedURL(ctx context.Context, httpMethod, name, a
 	}
 
 	if authTime == nil {
-		authTime = NewAuthTime(expired)
+		authTime = s.client.newAuthTime(expired)
 	}
 	signedHost := true
 	if len(signHost) > 0 {
@@ -292,7 +305,7 @@ func (s *ObjectService) GetPresignedURL2(ctx context.Context, httpMethod, name s
 	}
 
 	if authTime == nil {
-		authTime = NewAuthTime(expired)
+		authTime = s.client.newAuthTime(expired)
 	}
 	signedHost := true
 	if len(signHost) > 0 {
@@ -382,7 +395,7 @@ func (s *ObjectService) GetPresignedURL3(ctx context.Context, httpMethod, name s
 	}
 
 	if authTime == nil {
-		authTime = NewAuthTime(expired)
+		authTime = s.client.newAuthTime(expired)
 	}
 	signedHost := true
 	if len(signHost) > 0 {
@@ -434,7 +447,7 @@ func (s *ObjectService) GetSignature(ctx context.Context, httpMethod, name, ak,
 		return ""
 	}
 
-	authTime := NewAuthTime(expired)
+	authTime := s.client.newAuthTime(expired)
 	signedHost := true
 	if len(signHost) > 0 {
 		signedHost = signHost[0]
@@ -472,6 +485,10 @@ type ObjectPutHeaderOptions struct {
 
 	// 上传进度, ProgressCompleteEvent不能表示对应API调用成功，API是否调用成功的判断标准为返回err==nil
 	Listener ProgressListener `header:"-" url:"-" xml:"-"`
+	// 客户端压缩，设置后上传压缩数据并记录 Content-Encoding 和原始数据的长度、CRC64
+	Compressor Compressor `header:"-" url:"-" xml:"-"`
+	// 仅 PutFromFile 使用，目标对象与本地文件一致时跳过上传，是否跳过见 PutFromFileWithResult
+	SkipIfUnchanged UploadSkipStrategy `header:"-" url:"-" xml:"-"`
 }
 
 // ObjectPutOptions the options of put object
@@ -494,6 +511,10 @@ func (s *ObjectService) Put(ctx context.Context, name string, r io.Reader, uopt
 		return nil, err
 	}
 	opt := CloneObjectPutOptions(uopt)
+	s.client.applyUploadHeaderPolicy(name, opt.ObjectPutHeaderOptions)
+	if opt.Compressor != nil {
+		return s.putCompressed(ctx, name, r, opt)
+	}
 	totalBytes, err := GetReaderLen(r)
 	if err != nil && opt != nil && opt.Listener != nil {
 		if opt.ContentLength == 0 {
@@ -584,8 +605,32 @@ func (s *ObjectService) Put(ctx context.Context, name string, r io.Reader, uopt
 }
 
 // PutFromFile put object from local file
-func (s *ObjectService) PutFromFile(ctx context.Context, name string, filePath string, uopt *ObjectPutOptions) (resp *Response, err error) {
+func (s *ObjectService) PutFromFile(ctx context.Context, name string, filePath string, uopt *ObjectPutOptions) (*Response, error) {
+	_, resp, err := s.PutFromFileWithResult(ctx, name, filePath, uopt)
+	return resp, err
+}
+
+// PutFromFileResult 是 PutFromFileWithResult 的结果
+type PutFromFileResult struct {
+	// 设置了 SkipIfUnchanged 且目标对象与本地文件一致，跳过了上传，此时 Response 为目标对象的 Head 响应
+	Skipped bool
+}
+
+// PutFromFileWithResult 同 PutFromFile，额外返回是否因为 SkipIfUnchanged 跳过了上传
+func (s *ObjectService) PutFromFileWithResult(ctx context.Context, name string, filePath string, uopt *ObjectPutOptions) (res *PutFromFileResult, resp *Response, err error) {
+	res = &PutFromFileResult{}
 	opt := CloneObjectPutOptions(uopt)
+	s.client.applyUploadHeaders(name, filePath, opt.ObjectPutHeaderOptions)
+	if opt.SkipIfUnchanged != nil {
+		resp, res.Skipped, err = s.checkUploadSkip(ctx, name, filePath, opt.SkipIfUnchanged, opt.ObjectPutHeaderOptions)
+		if err != nil {
+			return nil, resp, err
+		}
+		if res.Skipped {
+			return
+		}
+		opt.SkipIfUnchanged = nil
+	}
 	nr := 0
 	for nr < 3 {
 		fd, e := os.Open(filePath)
@@ -600,7 +645,7 @@ func (s *ObjectService) PutFromFile(ctx context.Context, name string, filePath s
 			if s.client.Conf.RetryOpt.AutoSwitchHost {
 				// 收不到报文 或者 不存在RequestId
 				if resp == nil || resp.Header.Get("X-Cos-Request-Id") == "" {
-					opt.innerSwitchURL = toSwitchHost(s.client.BaseURL.BucketURL)
+					opt.innerSwitchURL = s.client.switchHost(s.client.BaseURL.BucketURL)
 				}
 			}
 			continue
@@ -670,7 +715,7 @@ func (s *ObjectService) Copy(ctx context.Context, name, sourceURL string, opt *O
 	if strings.HasPrefix(sourceURL, "http://") || strings.HasPrefix(sourceURL, "https://") {
 		return nil, nil, errors.New("sourceURL format is invalid.")
 	}
-	surl := strings.SplitN(sourceURL, "/", 2)
+	surl := s.splitCopySource(sourceURL)
 	if len(surl) < 2 {
 		return nil, nil, fmt.Errorf("x-cos-copy-source format error: %s", sourceURL)
 	}
@@ -1008,6 +1053,12 @@ type MultiUploadOptions struct {
 	DisableChecksum bool
 	WorkerChannel   chan<- *Jobs
 	ResultChannel   <-chan *Results
+	// 聚合所有分块的传输进度
+	Progress *TransferProgressOptions
+	// 客户端压缩，压缩到临时文件后上传，不支持断点续传
+	Compressor Compressor
+	// 目标对象与本地文件一致时跳过上传
+	SkipIfUnchanged UploadSkipStrategy
 }
 
 type MultiDownloadOptions struct {
@@ -1019,6 +1070,10 @@ type MultiDownloadOptions struct {
 	DisableChecksum bool
 	WorkerChannel   chan<- *Jobs
 	ResultChannel   <-chan *Results
+	// 聚合所有分块的传输进度
+	Progress *TransferProgressOptions
+	// 下载完成后按 Content-Encoding 解压本地文件，并校验原始数据的 CRC64
+	Decompress bool
 }
 
 type MultiDownloadCPInfo struct {
@@ -1145,7 +1200,7 @@ func worker(ctx context.Context, s *ObjectService, jobs <-chan *Jobs, results ch
 				if s.client.Conf.RetryOpt.AutoSwitchHost {
 					// 收不到报文 或者 不存在RequestId
 					if resp == nil || resp.Header.Get("X-Cos-Request-Id") == "" {
-						j.UpOpt.innerSwitchURL = toSwitchHost(s.client.BaseURL.BucketURL)
+						j.UpOpt.innerSwitchURL = s.client.switchHost(s.client.BaseURL.BucketURL)
 					}
 				}
 				time.Sleep(time.Millisecond)
@@ -1417,6 +1472,24 @@ func (s *ObjectService) Upload(ctx context.Context, name string, filepath string
 	if opt == nil {
 		opt = &MultiUploadOptions{}
 	}
+	uopt := *opt
+	uopt.OptIni = CloneInitiateMultipartUploadOptions(opt.OptIni)
+	s.client.applyUploadHeaders(name, filepath, uopt.OptIni.ObjectPutHeaderOptions)
+	uopt.PartSize, uopt.ThreadPoolSize = s.client.Conf.transferDefaults(uopt.PartSize, uopt.ThreadPoolSize)
+	opt = &uopt
+	if opt.SkipIfUnchanged != nil {
+		rsp, skipped, err := s.checkUploadSkip(ctx, name, filepath, opt.SkipIfUnchanged, opt.OptIni.ObjectPutHeaderOptions)
+		if err != nil {
+			return nil, rsp, err
+		}
+		if skipped {
+			return s.skippedUploadResult(name, rsp), rsp, nil
+		}
+		opt.SkipIfUnchanged = nil
+	}
+	if opt.Compressor != nil {
+		return s.uploadCompressed(ctx, name, filepath, opt)
+	}
 	var localcrc uint64
 	// 1.Get the file chunk
 	totalBytes, chunks, partNum, err := SplitFileIntoChunks(filepath, opt.PartSize*1024*1024)
@@ -1435,6 +1508,7 @@ func (s *ObjectService) Upload(ctx context.Context, name string, filepath string
 			return nil, nil, err
 		}
 	}
+	tracker := NewTransferTracker(totalBytes, partNum, opt.Progress)
 	// filesize=0 , use simple upload
 	if partNum == 0 || partNum == 1 {
 		var opt0 *ObjectPutOptions
@@ -1445,10 +1519,25 @@ func (s *ObjectService) Upload(ctx context.Context, name string, filepath string
 				nil,
 			}
 		}
+		if tracker != nil {
+			if opt0 == nil {
+				opt0 = &ObjectPutOptions{}
+			}
+			hopt := &ObjectPutHeaderOptions{}
+			if opt0.ObjectPutHeaderOptions != nil {
+				*hopt = *opt0.ObjectPutHeaderOptions
+			}
+			hopt.Listener = tracker.partListener(1, hopt.Listener)
+			opt0.ObjectPutHeaderOptions = hopt
+		}
+		tracker.Start()
 		rsp, err := s.PutFromFile(ctx, name, filepath, opt0)
 		if err != nil {
+			tracker.Fail(err)
 			return nil, rsp, err
 		}
+		tracker.CompletePart(1, totalBytes)
+		tracker.Complete()
 		result := &CompleteMultipartUploadResult{
 			Location: fmt.Sprintf("%s/%s", s.client.BaseURL.BucketURL, name),
 			Key:      name,
@@ -1517,6 +1606,7 @@ func (s *ObjectService) Upload(ctx context.Context, name string, filepath string
 	}
 	event := newProgressEvent(ProgressStartedEvent, 0, 0, totalBytes)
 	progressCallback(listener, event)
+	tracker.Start()
 
 	// 4.Push jobs
 	go func() {
@@ -1532,6 +1622,7 @@ func (s *ObjectService) Upload(ctx context.Context, name string, filepath string
 				partOpt.XCosTrafficLimit = optini.XCosTrafficLimit
 				partOpt.XOptionHeader = optini.XOptionHeader
 			}
+			partOpt.Listener = tracker.partListener(chunk.Number, nil)
 			job := &Jobs{
 				Name:       name,
 				RetryTimes: 3,
@@ -1562,6 +1653,7 @@ func (s *ObjectService) Upload(ctx context.Context, name string, filepath string
 				consumedBytes += chunks[i].Size
 				event = newProgressEvent(ProgressDataEvent, chunks[i].Size, consumedBytes, totalBytes)
 				progressCallback(listener, event)
+				tracker.CompletePart(chunks[i].Number, chunks[i].Size)
 			}
 			continue
 		}
@@ -1586,6 +1678,7 @@ func (s *ObjectService) Upload(ctx context.Context, name string, filepath string
 			consumedBytes += chunks[res.PartNumber-1].Size
 			event = newProgressEvent(ProgressDataEvent, chunks[res.PartNumber-1].Size, consumedBytes, totalBytes)
 			progressCallback(listener, event)
+			tracker.CompletePart(res.PartNumber, chunks[res.PartNumber-1].Size)
 		}
 	}
 	if !useExternalWorker {
@@ -1594,6 +1687,7 @@ func (s *ObjectService) Upload(ctx context.Context, name string, filepath string
 	if err != nil {
 		event = newProgressEvent(ProgressFailedEvent, 0, consumedBytes, totalBytes, err)
 		progressCallback(listener, event)
+		tracker.Fail(err)
 		return nil, nil, err
 	}
 	sort.Sort(ObjectList(optcom.Parts))
@@ -1603,16 +1697,21 @@ func (s *ObjectService) Upload(ctx context.Context, name string, filepath string
 
 	v, resp, err := s.CompleteMultipartUpload(context.Background(), name, uploadID, optcom)
 	if err != nil {
+		tracker.Fail(err)
 		return v, resp, err
 	}
 
 	if resp != nil && s.client.Conf.EnableCRC && !opt.DisableChecksum {
 		scoscrc := resp.Header.Get("x-cos-hash-crc64ecma")
-		icoscrc, err := strconv.ParseUint(scoscrc, 10, 64)
+		icoscrc, perr := strconv.ParseUint(scoscrc, 10, 64)
 		if icoscrc != localcrc {
-			return v, resp, fmt.Errorf("verification failed, want:%v, return:%v, x-cos-hash-crc64ecma: %v, err:%v, header:%+v", localcrc, icoscrc, scoscrc, err, resp.Header)
+			err = fmt.Errorf("verification failed, want:%v, return:%v, x-cos-hash-crc64ecma: %v, err:%v, header:%+v", localcrc, icoscrc, scoscrc, perr, resp.Header)
+			tracker.Fail(err)
+			return v, resp, err
 		}
 	}
+	// 合并分块且校验通过后才视为传输完成
+	tracker.Complete()
 	return v, resp, err
 }
 
@@ -1620,6 +1719,10 @@ func (s *ObjectService) UploadWithPicOperations(ctx context.Context, name string
 	if opt == nil {
 		opt = &MultiUploadOptions{}
 	}
+	uopt := *opt
+	uopt.OptIni = CloneInitiateMultipartUploadOptions(opt.OptIni)
+	s.client.applyUploadHeaders(name, filepath, uopt.OptIni.ObjectPutHeaderOptions)
+	opt = &uopt
 	var localcrc uint64
 	// 1.Get the file chunk
 	totalBytes, chunks, partNum, err := SplitFileIntoChunks(filepath, opt.PartSize*1024*1024)
@@ -1638,6 +1741,7 @@ func (s *ObjectService) UploadWithPicOperations(ctx context.Context, name string
 			return nil, nil, err
 		}
 	}
+	tracker := NewTransferTracker(totalBytes, partNum, opt.Progress)
 	// filesize=0 , use simple upload
 	if partNum == 0 || partNum == 1 {
 		var opt0 *ObjectPutOptions
@@ -1648,10 +1752,25 @@ func (s *ObjectService) UploadWithPicOperations(ctx context.Context, name string
 				nil,
 			}
 		}
+		if tracker != nil {
+			if opt0 == nil {
+				opt0 = &ObjectPutOptions{}
+			}
+			hopt := &ObjectPutHeaderOptions{}
+			if opt0.ObjectPutHeaderOptions != nil {
+				*hopt = *opt0.ObjectPutHeaderOptions
+			}
+			hopt.Listener = tracker.partListener(1, hopt.Listener)
+			opt0.ObjectPutHeaderOptions = hopt
+		}
+		tracker.Start()
 		rsp, err := s.PutFromFile(ctx, name, filepath, opt0)
 		if err != nil {
+			tracker.Fail(err)
 			return nil, rsp, err
 		}
+		tracker.CompletePart(1, totalBytes)
+		tracker.Complete()
 		result := &CompleteMultipartUploadResult{
 			Location: fmt.Sprintf("%s/%s", s.client.BaseURL.BucketURL, name),
 			Key:      name,
@@ -1715,6 +1834,7 @@ func (s *ObjectService) UploadWithPicOperations(ctx context.Context, name string
 	}
 	event := newProgressEvent(ProgressStartedEvent, 0, 0, totalBytes)
 	progressCallback(listener, event)
+	tracker.Start()
 
 	// 4.Push jobs
 	go func() {
@@ -1730,6 +1850,7 @@ func (s *ObjectService) UploadWithPicOperations(ctx context.Context, name string
 				partOpt.XCosTrafficLimit = optini.XCosTrafficLimit
 				partOpt.XOptionHeader = optini.XOptionHeader
 			}
+			partOpt.Listener = tracker.partListener(chunk.Number, nil)
 			job := &Jobs{
 				Name:       name,
 				RetryTimes: 3,
@@ -1754,6 +1875,7 @@ func (s *ObjectService) UploadWithPicOperations(ctx context.Context, name string
 				consumedBytes += chunks[i].Size
 				event = newProgressEvent(ProgressDataEvent, chunks[i].Size, consumedBytes, totalBytes)
 				progressCallback(listener, event)
+				tracker.CompletePart(chunks[i].Number, chunks[i].Size)
 			}
 			continue
 		}
@@ -1773,12 +1895,14 @@ func (s *ObjectService) UploadWithPicOperations(ctx context.Context, name string
 			consumedBytes += chunks[res.PartNumber-1].Size
 			event = newProgressEvent(ProgressDataEvent, chunks[res.PartNumber-1].Size, consumedBytes, totalBytes)
 			progressCallback(listener, event)
+			tracker.CompletePart(res.PartNumber, chunks[res.PartNumber-1].Size)
 		}
 	}
 	close(chresults)
 	if err != nil {
 		event = newProgressEvent(ProgressFailedEvent, 0, consumedBytes, totalBytes, err)
 		progressCallback(listener, event)
+		tracker.Fail(err)
 		return nil, nil, err
 	}
 	sort.Sort(ObjectList(optcom.Parts))
@@ -1793,16 +1917,21 @@ func (s *ObjectService) UploadWithPicOperations(ctx context.Context, name string
 
 	v, resp, err := s.CompleteMultipartUpload(context.Background(), name, uploadID, optcom)
 	if err != nil {
+		tracker.Fail(err)
 		return v, resp, err
 	}
 
 	if resp != nil && s.client.Conf.EnableCRC && !opt.DisableChecksum {
 		scoscrc := resp.Header.Get("x-cos-hash-crc64ecma")
-		icoscrc, err := strconv.ParseUint(scoscrc, 10, 64)
+		icoscrc, perr := strconv.ParseUint(scoscrc, 10, 64)
 		if icoscrc != localcrc {
-			return v, resp, fmt.Errorf("verification failed, want:%v, return:%v, x-cos-hash-crc64ecma: %v, err:%v, header:%+v", localcrc, icoscrc, scoscrc, err, resp.Header)
+			err = fmt.Errorf("verification failed, want:%v, return:%v, x-cos-hash-crc64ecma: %v, err:%v, header:%+v", localcrc, icoscrc, scoscrc, perr, resp.Header)
+			tracker.Fail(err)
+			return v, resp, err
 		}
 	}
+	// 合并分块且校验通过后才视为传输完成
+	tracker.Complete()
 	return v, resp, err
 }
 
@@ -1923,9 +2052,15 @@ func (s *ObjectService) Download(ctx context.Context, name string, filepath stri
 	if opt == nil {
 		opt = &MultiDownloadOptions{}
 	}
+	dopt := *opt
+	dopt.PartSize, dopt.ThreadPoolSize = s.client.Conf.transferDefaults(dopt.PartSize, dopt.ThreadPoolSize)
+	opt = &dopt
 	if opt.Opt != nil && opt.Opt.Range != "" {
 		return nil, fmt.Errorf("Download doesn't support Range Options")
 	}
+	if opt.Decompress || (opt.Opt != nil && opt.Opt.Decompress) {
+		return s.downloadDecompressed(ctx, name, filepath, opt, id...)
+	}
 	headOpt := &ObjectHeadOptions{}
 	if opt.Opt != nil {
 		headOpt.XCosSSECustomerAglo = opt.Opt.XCosSSECustomerAglo
@@ -1951,9 +2086,22 @@ func (s *ObjectService) Download(ctx context.Context, name string, filepath stri
 	if err != nil {
 		return resp, err
 	}
+	tracker := NewTransferTracker(totalBytes, partNum, opt.Progress)
 	// 直接下载到文件，GetToFile 内部已做 CRC 校验，无需重复校验
 	if partNum == 0 || partNum == 1 {
-		rsp, err := s.GetToFile(ctx, name, filepath, opt.Opt, id...)
+		gopt := opt.Opt
+		if tracker != nil {
+			gopt = CloneObjectGetOptions(opt.Opt)
+			gopt.Listener = tracker.partListener(1, gopt.Listener)
+		}
+		tracker.Start()
+		rsp, err := s.GetToFile(ctx, name, filepath, gopt, id...)
+		if err != nil {
+			tracker.Fail(err)
+			return rsp, err
+		}
+		tracker.CompletePart(1, totalBytes)
+		tracker.Complete()
 		return rsp, err
 	}
 	// 断点续载
@@ -2016,6 +2164,7 @@ func (s *ObjectService) Download(ctx context.Context, name string, filepath stri
 	}
 	event := newProgressEvent(ProgressStartedEvent, 0, 0, totalBytes)
 	progressCallback(listener, event)
+	tracker.Start()
 
 	go func() {
 		for _, chunk := range chunks {
@@ -2027,6 +2176,7 @@ func (s *ObjectService) Download(ctx context.Context, name string, filepath stri
 				downOpt = *opt.Opt
 				downOpt.Listener = nil // listener need to set nil
 			}
+			downOpt.Listener = tracker.partListener(chunk.Number, nil)
 			job := &Jobs{
 				Name:       name,
 				RetryTimes: 3,
@@ -2058,6 +2208,7 @@ func (s *ObjectService) Download(ctx context.Context, name string, filepath stri
 				consumedBytes += chunks[i].Size
 				event = newProgressEvent(ProgressDataEvent, chunks[i].Size, consumedBytes, totalBytes)
 				progressCallback(listener, event)
+				tracker.CompletePart(chunks[i].Number, chunks[i].Size)
 			}
 			continue
 		}
@@ -2084,6 +2235,7 @@ func (s *ObjectService) Download(ctx context.Context, name string, filepath stri
 		consumedBytes += chunks[res.PartNumber-1].Size
 		event = newProgressEvent(ProgressDataEvent, chunks[res.PartNumber-1].Size, consumedBytes, totalBytes)
 		progressCallback(listener, event)
+		tracker.CompletePart(res.PartNumber, chunks[res.PartNumber-1].Size)
 	}
 	if !useExternalWorker {
 		close(chresults)
@@ -2106,6 +2258,7 @@ func (s *ObjectService) Download(ctx context.Context, name string, filepath stri
 	}
 	if syncErr != nil {
 		dlfd.Close()
+		tracker.Fail(syncErr)
 		if err != nil {
 			return nil, fmt.Errorf("sync failed: %v; download error: %w", syncErr, err)
 		}
@@ -2116,6 +2269,7 @@ func (s *ObjectService) Download(ctx context.Context, name string, filepath stri
 		dlfd.Close()
 		event = newProgressEvent(ProgressFailedEvent, 0, consumedBytes, totalBytes, err)
 		progressCallback(listener, event)
+		tracker.Fail(err)
 		return nil, err
 	}
 	// 整个下载成功，删除 checkpoint 文件
@@ -2149,7 +2303,9 @@ func (s *ObjectService) Download(ctx context.Context, name string, filepath stri
 		}
 		if localcrc != icoscrc {
 			dlfd.Close()
-			return resp, fmt.Errorf("verification failed, want:%v, return:%v, header:%+v", icoscrc, localcrc, resp.Header)
+			err = fmt.Errorf("verification failed, want:%v, return:%v, header:%+v", icoscrc, localcrc, resp.Header)
+			tracker.Fail(err)
+			return resp, err
 		}
 	}
 	err = dlfd.Close()
@@ -2158,6 +2314,7 @@ func (s *ObjectService) Download(ctx context.Context, name string, filepath stri
 	}
 	event = newProgressEvent(ProgressCompletedEvent, 0, consumedBytes, totalBytes)
 	progressCallback(listener, event)
+	tracker.Complete()
 
 	return resp, err
 }
@@ -2382,14 +2539,38 @@ type ObjectPutFromURLOptions struct {
 	PartSize    int
 	QueueSize   int
 	InitOptions *InitiateMultipartUploadOptions
+	// 源站支持 Range 时并发下载、上传分块的数量，默认为 1
+	ThreadPoolSize int
+	// 单个分块下载失败时的重试次数，默认为 3
+	RetryTimes int
+	// 请求源站使用的 http.Client，默认为 http.DefaultClient，整体超时可以通过 Client.Timeout 配置
+	SourceClient *http.Client
+	// 请求源站时附加的头部，例如 Authorization
+	SourceHeader http.Header
+	// 单个分块下载的超时时间，仅在源站支持 Range 时生效，默认不限制
+	RangeTimeout time.Duration
 }
 
+// ErrSourceChanged 表示 PutFromURL 按分块下载的过程中源站的对象发生了变化
+var ErrSourceChanged = errors.New("source object changed during download")
+
+const (
+	// PutFromURL 分块重试的初始间隔，Client 的 RetryOpt.Interval 为 0 时使用，之后每次加倍
+	defaultRangeRetryInterval = 100 * time.Millisecond
+	maxRangeRetryInterval     = 5 * time.Second
+)
+
+// PutFromURL 从 downloadURL 下载数据并分块上传到 name。
+// 使用 Range 请求探测源站，支持 Range 时按分块并发下载和上传，单个分块失败时重试；否则流式下载、顺序上传。
+// 分块请求通过 If-Range 绑定探测时的 ETag 或 Last-Modified，源站对象发生变化时返回 ErrSourceChanged。
 func (s *ObjectService) PutFromURL(ctx context.Context, name string, downloadURL string, opt *ObjectPutFromURLOptions) (*CompleteMultipartUploadResult, *Response, error) {
 	if opt == nil {
 		opt = &ObjectPutFromURLOptions{}
 	}
+	initOpt := CloneInitiateMultipartUploadOptions(opt.InitOptions)
+	s.client.applyUploadHeaders(name, "", initOpt.ObjectPutHeaderOptions)
 	// init
-	v, resp, err := s.InitiateMultipartUpload(ctx, name, opt.InitOptions)
+	v, resp, err := s.InitiateMultipartUpload(ctx, name, initOpt)
 	if err != nil {
 		return nil, resp, err
 	}
@@ -2400,27 +2581,104 @@ func (s *ObjectService) PutFromURL(ctx context.Context, name string, downloadURL
 			s.AbortMultipartUpload(ctx, name, uploadId, nil)
 		}
 	}()
-	// request from url
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
-	if err != nil {
+	// request from url, 探测是否支持 Range
+	rsp, err := getFromSource(ctx, downloadURL, opt, "bytes=0-0", "")
+	if err != nil || rsp == nil {
 		isErr = true
 		return nil, nil, err
 	}
-	rsp, err := http.DefaultClient.Do(req)
-	if err != nil || rsp == nil {
+	totalBytes := int64(-1)
+	var validator string
+	if rsp.StatusCode == http.StatusPartialContent {
+		if _, _, total, e := parseContentRange(rsp.Header.Get("Content-Range")); e == nil {
+			totalBytes = total
+		}
+		validator = sourceValidator(rsp.Header)
+	}
+	if rsp.StatusCode == http.StatusPartialContent || rsp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		rsp.Body.Close()
+		if totalBytes < 0 {
+			// 总长度未知或者空文件，重新请求整个对象
+			rsp, err = getFromSource(ctx, downloadURL, opt, "", "")
+			if err != nil || rsp == nil {
+				isErr = true
+				return nil, nil, err
+			}
+		}
+	}
+
+	comOpt := &CompleteMultipartUploadOptions{}
+	if totalBytes >= 0 {
+		comOpt.Parts, resp, err = s.putFromURLRanges(ctx, name, uploadId, downloadURL, validator, totalBytes, opt)
+	} else {
+		defer rsp.Body.Close()
+		if rsp.StatusCode > 299 {
+			isErr = true
+			return nil, &Response{rsp}, fmt.Errorf("the status code of downloadURL response is failed: %d", rsp.StatusCode)
+		}
+		comOpt.Parts, resp, err = s.putStreamParts(ctx, name, uploadId, rsp.Body, opt.PartSize, opt.QueueSize, "url download failed")
+	}
+	if err != nil {
 		isErr = true
-		return nil, nil, err
+		return nil, resp, err
 	}
-	defer rsp.Body.Close()
-	if rsp.StatusCode > 299 {
+	// 兼容0字节文件，如果没有上传分片，则上传一个空分片
+	if len(comOpt.Parts) == 0 {
+		resp, err := s.UploadPart(ctx, name, uploadId, 1, http.NoBody, nil)
+		if err != nil {
+			isErr = true
+			return nil, resp, err
+		}
+		comOpt.Parts = append(comOpt.Parts, Object{
+			PartNumber: 1,
+			ETag:       resp.Header.Get("ETag"),
+		})
+	}
+	res, resp, err := s.CompleteMultipartUpload(ctx, name, uploadId, comOpt)
+	if err != nil {
 		isErr = true
-		return nil, &Response{rsp}, fmt.Errorf("the status code of downloadURL response is failed: %d", rsp.StatusCode)
 	}
-	factory := newPartFactory(opt.PartSize, opt.QueueSize)
-	partChannel, errChannel := factory.Produce(rsp.Body)
+	return res, resp, err
+}
+
+// sourceValidator 返回探测响应中可用于 If-Range 的强 ETag 或 Last-Modified，弱 ETag 不能用于 If-Range
+func sourceValidator(header http.Header) string {
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return header.Get("Last-Modified")
+}
+
+func getFromSource(ctx context.Context, downloadURL string, opt *ObjectPutFromURLOptions, rangeStr, ifRange string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range opt.SourceHeader {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	if rangeStr != "" {
+		req.Header.Set("Range", rangeStr)
+	}
+	if ifRange != "" {
+		req.Header.Set("If-Range", ifRange)
+	}
+	c := opt.SourceClient
+	if c == nil {
+		c = http.DefaultClient
+	}
+	return c.Do(req)
+}
+
+// putStreamParts 将 body 按 partSize(MB) 切分，顺序上传分块，读取 body 失败时使用 readErrPrefix 描述错误
+func (s *ObjectService) putStreamParts(ctx context.Context, name, uploadId string, body io.ReadCloser, partSize, queueSize int, readErrPrefix string) ([]Object, *Response, error) {
+	factory := newPartFactory(partSize, queueSize)
+	partChannel, errChannel := factory.Produce(body)
 	defer factory.Close()
 
-	comOpt := &CompleteMultipartUploadOptions{}
+	var parts []Object
 	var partNumber int
 	for {
 		select {
@@ -2432,10 +2690,9 @@ func (s *ObjectService) PutFromURL(ctx context.Context, name string, downloadURL
 			partNumber++
 			resp, err := s.UploadPart(ctx, name, uploadId, partNumber, part, nil)
 			if err != nil {
-				isErr = true
 				return nil, resp, err
 			}
-			comOpt.Parts = append(comOpt.Parts, Object{
+			parts = append(parts, Object{
 				PartNumber: partNumber,
 				ETag:       resp.Header.Get("ETag"),
 			})
@@ -2445,31 +2702,145 @@ func (s *ObjectService) PutFromURL(ctx context.Context, name string, downloadURL
 				break
 			}
 			if err != nil {
-				isErr = true
-				return nil, nil, fmt.Errorf("url download failed: %v", err)
+				return nil, nil, fmt.Errorf("%s: %w", readErrPrefix, err)
 			}
 		}
 		if partChannel == nil && errChannel == nil {
 			break
 		}
 	}
-	// 兼容0字节文件，如果没有上传分片，则上传一个空分片
-	if len(comOpt.Parts) == 0 {
-		resp, err := s.UploadPart(ctx, name, uploadId, 1, http.NoBody, nil)
-		if err != nil {
-			isErr = true
-			return nil, resp, err
+	return parts, nil, nil
+}
+
+// putFromURLRanges 按分块并发下载源站的 Range 并上传，validator 为探测时源站对象的 ETag 或 Last-Modified
+func (s *ObjectService) putFromURLRanges(ctx context.Context, name, uploadId, downloadURL, validator string, totalBytes int64, opt *ObjectPutFromURLOptions) ([]Object, *Response, error) {
+	partSize := int64(opt.PartSize) * 1024 * 1024
+	if partSize <= 0 {
+		partSize = 8 * 1024 * 1024
+	}
+	// 分块数不超过 10000
+	if totalBytes/partSize >= 10000 {
+		partSize = (totalBytes/10000/(1024*1024) + 1) * 1024 * 1024
+	}
+	chunks, _, err := SplitSizeIntoChunks(totalBytes, partSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	poolSize := opt.ThreadPoolSize
+	if poolSize <= 0 {
+		poolSize = 1
+	}
+	retryTimes := opt.RetryTimes
+	if retryTimes <= 0 {
+		retryTimes = 3
+	}
+	interval := s.client.Conf.RetryOpt.Interval
+	if interval <= 0 {
+		interval = defaultRangeRetryInterval
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	chjobs := make(chan Chunk, len(chunks))
+	for _, chunk := range chunks {
+		chjobs <- chunk
+	}
+	close(chjobs)
+	chresults := make(chan *Results, len(chunks))
+	for w := 0; w < poolSize; w++ {
+		go func() {
+			for chunk := range chjobs {
+				res := &Results{PartNumber: chunk.Number}
+				var data []byte
+				data, res.err = fetchSourceRange(ctx, downloadURL, validator, chunk, retryTimes, interval, opt)
+				if res.err == nil {
+					res.Resp, res.err = s.UploadPart(ctx, name, uploadId, chunk.Number, bytes.NewReader(data), nil)
+				}
+				if res.err != nil {
+					cancel()
+				}
+				chresults <- res
+			}
+		}()
+	}
+
+	var parts []Object
+	var resp *Response
+	for range chunks {
+		res := <-chresults
+		if res.err != nil {
+			if err == nil {
+				err, resp = res.err, res.Resp
+			}
+			continue
 		}
-		comOpt.Parts = append(comOpt.Parts, Object{
-			PartNumber: 1,
-			ETag:       resp.Header.Get("ETag"),
+		parts = append(parts, Object{
+			PartNumber: res.PartNumber,
+			ETag:       res.Resp.Header.Get("ETag"),
 		})
 	}
-	res, resp, err := s.CompleteMultipartUpload(ctx, name, uploadId, comOpt)
 	if err != nil {
-		isErr = true
+		return nil, resp, err
 	}
-	return res, resp, err
+	sort.Sort(ObjectList(parts))
+	return parts, nil, nil
+}
+
+// fetchSourceRange 下载源站的一个分块，失败时按 interval 指数退避重试。
+// 源站对象发生变化（If-Range 不匹配返回 200，或返回 412）时不重试
+func fetchSourceRange(ctx context.Context, downloadURL, validator string, chunk Chunk, retryTimes int, interval time.Duration, opt *ObjectPutFromURLOptions) ([]byte, error) {
+	rangeStr := FormatRangeOptions(&RangeOptions{
+		HasStart: true,
+		HasEnd:   true,
+		Start:    chunk.OffSet,
+		End:      chunk.OffSet + chunk.Size - 1,
+	})
+	var err error
+	for nr := 0; nr < retryTimes; nr++ {
+		if nr > 0 {
+			if serr := sleepWithContext(ctx, interval); serr != nil {
+				return nil, serr
+			}
+			if interval *= 2; interval > maxRangeRetryInterval {
+				interval = maxRangeRetryInterval
+			}
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var data []byte
+		data, err = func() ([]byte, error) {
+			rctx := ctx
+			if opt.RangeTimeout > 0 {
+				var cancel context.CancelFunc
+				rctx, cancel = context.WithTimeout(ctx, opt.RangeTimeout)
+				defer cancel()
+			}
+			rsp, err := getFromSource(rctx, downloadURL, opt, rangeStr, validator)
+			if err != nil {
+				return nil, err
+			}
+			defer rsp.Body.Close()
+			if (validator != "" && rsp.StatusCode == http.StatusOK) || rsp.StatusCode == http.StatusPreconditionFailed {
+				return nil, fmt.Errorf("%w: range %s response status code: %d", ErrSourceChanged, rangeStr, rsp.StatusCode)
+			}
+			if rsp.StatusCode != http.StatusPartialContent {
+				return nil, fmt.Errorf("the status code of downloadURL range %s response is failed: %d", rangeStr, rsp.StatusCode)
+			}
+			data := make([]byte, chunk.Size)
+			if _, err = io.ReadFull(rsp.Body, data); err != nil {
+				return nil, err
+			}
+			return data, nil
+		}()
+		if err == nil {
+			return data, nil
+		}
+		if errors.Is(err, ErrSourceChanged) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("url download range %s failed: %v", rangeStr, err)
 }
 
 type partFactory struct {
@@ -0,0 +1,310 @@
+package cos
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc64"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ArchiveFormat 是 PutArchive 生成的归档格式
+type ArchiveFormat string
+
+const (
+	ArchiveZip   ArchiveFormat = "zip"
+	ArchiveTarGz ArchiveFormat = "tar.gz"
+)
+
+// ArchiveEntry 是归档中的一个条目，FilePath、Reader、Object 三选一
+type ArchiveEntry struct {
+	// 归档内的路径
+	Name string
+	// 本地文件
+	FilePath string
+	// 任意数据流，tar.gz 格式需要 Size，Size 小于等于 0 时会先读入内存
+	Reader io.Reader
+	Size   int64
+	// 同一存储桶中的对象，通过 Get 下载
+	Object    string
+	VersionId string
+	// 为空时使用本地文件或者对象的修改时间，仍未知时使用当前时间
+	ModTime time.Time
+	// 默认为 0644
+	Mode os.FileMode
+}
+
+// ArchiveManifestEntry 记录归档中的一个条目
+type ArchiveManifestEntry struct {
+	Name    string    `json:"name"`
+	Source  string    `json:"source"`
+	Size    int64     `json:"size"`
+	CRC64   uint64    `json:"crc64ecma"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// ArchiveManifest 是归档内容的清单
+type ArchiveManifest struct {
+	Format  ArchiveFormat          `json:"format"`
+	Entries []ArchiveManifestEntry `json:"entries"`
+}
+
+// PutArchiveOptions 是 PutArchive 的选项
+type PutArchiveOptions struct {
+	// 分块大小，单位 MB，默认 8MB
+	PartSize int
+	// 已生成、等待上传的分块数量，默认 10
+	QueueSize   int
+	InitOptions *InitiateMultipartUploadOptions
+	// 不为空时将清单以 JSON 格式写入归档的该路径下，作为最后一个条目
+	ManifestName string
+}
+
+// PutArchiveResult 是 PutArchive 的结果
+type PutArchiveResult struct {
+	*CompleteMultipartUploadResult
+	// 归档对象的长度
+	Size     int64
+	Manifest *ArchiveManifest
+}
+
+// PutArchive 将本地文件、数据流或者存储桶中的对象流式打包为 zip 或 tar.gz，边打包边分块上传到 name，不落盘。
+func (s *ObjectService) PutArchive(ctx context.Context, name string, format ArchiveFormat, entries []ArchiveEntry, opt ...*PutArchiveOptions) (*PutArchiveResult, *Response, error) {
+	var aopt PutArchiveOptions
+	if len(opt) > 0 && opt[0] != nil {
+		aopt = *opt[0]
+	}
+	contentType := "application/zip"
+	switch format {
+	case ArchiveZip:
+	case ArchiveTarGz:
+		contentType = "application/gzip"
+	default:
+		return nil, nil, fmt.Errorf("unsupported archive format: %v", format)
+	}
+	for i := range entries {
+		if entries[i].Name == "" {
+			return nil, nil, fmt.Errorf("archive entry %d name is empty", i)
+		}
+		var sources int
+		for _, ok := range []bool{entries[i].FilePath != "", entries[i].Reader != nil, entries[i].Object != ""} {
+			if ok {
+				sources++
+			}
+		}
+		if sources != 1 {
+			return nil, nil, fmt.Errorf("archive entry %v must have exactly one of FilePath, Reader and Object", entries[i].Name)
+		}
+	}
+	initOpt := CloneInitiateMultipartUploadOptions(aopt.InitOptions)
+	s.client.applyUploadHeaderPolicy(name, initOpt.ObjectPutHeaderOptions)
+	if initOpt.ContentType == "" {
+		initOpt.ContentType = contentType
+	}
+	v, resp, err := s.InitiateMultipartUpload(ctx, name, initOpt)
+	if err != nil {
+		return nil, resp, err
+	}
+	uploadId := v.UploadID
+
+	pr, pw := io.Pipe()
+	aw := &archiveWriter{
+		s:        s,
+		ctx:      ctx,
+		format:   format,
+		manifest: &ArchiveManifest{Format: format},
+		counter:  &countWriter{w: pw},
+	}
+	go func() {
+		pw.CloseWithError(aw.write(entries, aopt.ManifestName))
+	}()
+	parts, resp, err := s.putStreamParts(ctx, name, uploadId, pr, aopt.PartSize, aopt.QueueSize, "archive failed")
+	// 上传失败时关闭读端，结束打包
+	pr.CloseWithError(errors.New("archive upload aborted"))
+	if err == nil && len(parts) == 0 {
+		err = errors.New("archive is empty")
+	}
+	if err != nil {
+		s.AbortMultipartUpload(ctx, name, uploadId, nil)
+		return nil, resp, err
+	}
+	res, resp, err := s.CompleteMultipartUpload(ctx, name, uploadId, &CompleteMultipartUploadOptions{Parts: parts})
+	if err != nil {
+		s.AbortMultipartUpload(ctx, name, uploadId, nil)
+		return nil, resp, err
+	}
+	return &PutArchiveResult{
+		CompleteMultipartUploadResult: res,
+		Size:                          aw.counter.n,
+		Manifest:                      aw.manifest,
+	}, resp, nil
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type archiveWriter struct {
+	s        *ObjectService
+	ctx      context.Context
+	format   ArchiveFormat
+	manifest *ArchiveManifest
+	counter  *countWriter
+	zw       *zip.Writer
+	gw       *gzip.Writer
+	tw       *tar.Writer
+}
+
+func (a *archiveWriter) write(entries []ArchiveEntry, manifestName string) error {
+	if a.format == ArchiveZip {
+		a.zw = zip.NewWriter(a.counter)
+	} else {
+		a.gw = gzip.NewWriter(a.counter)
+		a.tw = tar.NewWriter(a.gw)
+	}
+	for i := range entries {
+		if err := a.writeEntry(&entries[i]); err != nil {
+			return fmt.Errorf("archive entry %v: %w", entries[i].Name, err)
+		}
+	}
+	if manifestName != "" {
+		b, err := json.Marshal(a.manifest)
+		if err != nil {
+			return err
+		}
+		if err = a.add(manifestName, time.Now(), 0644, int64(len(b)), bytes.NewReader(b)); err != nil {
+			return err
+		}
+	}
+	if a.zw != nil {
+		return a.zw.Close()
+	}
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	return a.gw.Close()
+}
+
+func (a *archiveWriter) writeEntry(e *ArchiveEntry) error {
+	var r io.Reader
+	var source string
+	size := int64(-1)
+	modTime := e.ModTime
+	switch {
+	case e.FilePath != "":
+		fd, err := os.Open(e.FilePath)
+		if err != nil {
+			return err
+		}
+		defer fd.Close()
+		stat, err := fd.Stat()
+		if err != nil {
+			return err
+		}
+		r, size, source = fd, stat.Size(), "file://"+e.FilePath
+		if modTime.IsZero() {
+			modTime = stat.ModTime()
+		}
+	case e.Object != "":
+		var id []string
+		if e.VersionId != "" {
+			id = append(id, e.VersionId)
+		}
+		resp, err := a.s.Get(a.ctx, e.Object, nil, id...)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if v, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64); err == nil {
+			size = v
+		}
+		if modTime.IsZero() {
+			modTime, _ = ParseObjectTime(resp.Header.Get("Last-Modified"))
+		}
+		r, source = resp.Body, "cos://"+e.Object
+		if e.VersionId != "" {
+			source += "?versionId=" + e.VersionId
+		}
+	default:
+		r, source = e.Reader, "reader"
+		if e.Size > 0 {
+			size = e.Size
+		}
+	}
+	if size < 0 && a.tw != nil {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		r, size = bytes.NewReader(b), int64(len(b))
+	}
+	if modTime.IsZero() {
+		modTime = time.Now()
+	}
+	mode := e.Mode
+	if mode == 0 {
+		mode = 0644
+	}
+	crc := crc64.New(crc64.MakeTable(crc64.ECMA))
+	cr := &countWriter{w: crc}
+	if err := a.add(e.Name, modTime, mode, size, io.TeeReader(r, cr)); err != nil {
+		return err
+	}
+	if size >= 0 && cr.n != size {
+		return fmt.Errorf("size mismatch, want: %v, got: %v", size, cr.n)
+	}
+	a.manifest.Entries = append(a.manifest.Entries, ArchiveManifestEntry{
+		Name:    e.Name,
+		Source:  source,
+		Size:    cr.n,
+		CRC64:   crc.Sum64(),
+		ModTime: modTime.UTC(),
+	})
+	return nil
+}
+
+func (a *archiveWriter) add(name string, modTime time.Time, mode os.FileMode, size int64, r io.Reader) error {
+	if a.zw != nil {
+		fh := &zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: modTime,
+		}
+		fh.SetMode(mode)
+		w, err := a.zw.CreateHeader(fh)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w, r)
+		return err
+	}
+	err := a.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     int64(mode.Perm()),
+		ModTime:  modTime,
+	})
+	if err != nil {
+		return err
+	}
+	// 多余的数据会导致 tar.ErrWriteTooLong，不足时由长度校验发现
+	_, err = io.Copy(a.tw, r)
+	return err
+}
@@ -0,0 +1,181 @@
+package cos
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"hash/crc64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testArchiveServer(t *testing.T, remote []byte) func() []byte {
+	var mu sync.Mutex
+	parts := map[int][]byte{}
+	var archive []byte
+	mux.HandleFunc("/remote.txt", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		w.Header().Set("Last-Modified", "Mon, 12 Jun 2017 05:36:19 GMT")
+		w.Write(remote)
+	})
+	mux.HandleFunc("/bundle", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch {
+		case r.Method == http.MethodPost && r.Form.Get("uploadId") == "":
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>archive</UploadId></InitiateMultipartUploadResult>`)
+		case r.Method == http.MethodPut:
+			bs, _ := ioutil.ReadAll(r.Body)
+			n, _ := strconv.Atoi(r.Form.Get("partNumber"))
+			mu.Lock()
+			parts[n] = bs
+			mu.Unlock()
+			w.Header().Set("ETag", fmt.Sprintf("\"etag%d\"", n))
+			w.Header().Set("x-cos-hash-crc64ecma", strconv.FormatUint(crc64.Checksum(bs, crc64.MakeTable(crc64.ECMA)), 10))
+		case r.Method == http.MethodPost:
+			body := &CompleteMultipartUploadOptions{}
+			xml.NewDecoder(r.Body).Decode(body)
+			mu.Lock()
+			archive = nil
+			for _, p := range body.Parts {
+				archive = append(archive, parts[p.PartNumber]...)
+			}
+			mu.Unlock()
+			fmt.Fprint(w, `<CompleteMultipartUploadResult><Key>bundle</Key><ETag>"etag"</ETag></CompleteMultipartUploadResult>`)
+		}
+	})
+	return func() []byte {
+		mu.Lock()
+		defer mu.Unlock()
+		return archive
+	}
+}
+
+func TestObjectService_PutArchive(t *testing.T) {
+	setup()
+	defer teardown()
+
+	local := bytes.Repeat([]byte("local file\n"), 300000)
+	remote := []byte("remote object")
+	archive := testArchiveServer(t, remote)
+
+	filePath := "tmpfile" + time.Now().Format(time.RFC3339)
+	ioutil.WriteFile(filePath, local, 0644)
+	defer os.Remove(filePath)
+
+	want := map[string][]byte{
+		"dist/local.txt":  local,
+		"dist/remote.txt": remote,
+		"dist/stdin.txt":  []byte("from reader"),
+	}
+	for _, format := range []ArchiveFormat{ArchiveZip, ArchiveTarGz} {
+		entries := []ArchiveEntry{
+			{Name: "dist/local.txt", FilePath: filePath},
+			{Name: "dist/remote.txt", Object: "remote.txt"},
+			{Name: "dist/stdin.txt", Reader: strings.NewReader("from reader")},
+		}
+		res, _, err := client.Object.PutArchive(context.Background(), "bundle", format, entries, &PutArchiveOptions{
+			PartSize:     1,
+			ManifestName: "MANIFEST.json",
+		})
+		if err != nil {
+			t.Fatalf("Object.PutArchive(%v) returned error: %v", format, err)
+		}
+		data := archive()
+		if res.Size != int64(len(data)) || res.ETag != "\"etag\"" {
+			t.Errorf("Object.PutArchive(%v) returned Size: %v, ETag: %v, uploaded %v bytes", format, res.Size, res.ETag, len(data))
+		}
+		if len(res.Manifest.Entries) != 3 || res.Manifest.Entries[1].Source != "cos://remote.txt" {
+			t.Errorf("Object.PutArchive(%v) returned manifest %+v", format, res.Manifest)
+		}
+		if !res.Manifest.Entries[1].ModTime.Equal(time.Date(2017, 6, 12, 5, 36, 19, 0, time.UTC)) {
+			t.Errorf("remote entry ModTime: %v", res.Manifest.Entries[1].ModTime)
+		}
+
+		got := map[string][]byte{}
+		if format == ArchiveZip {
+			zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatalf("zip.NewReader returned error: %v", err)
+			}
+			for _, f := range zr.File {
+				rc, _ := f.Open()
+				got[f.Name], _ = ioutil.ReadAll(rc)
+				rc.Close()
+			}
+		} else {
+			gr, err := gzip.NewReader(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("gzip.NewReader returned error: %v", err)
+			}
+			tr := tar.NewReader(gr)
+			for {
+				h, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("tar.Next returned error: %v", err)
+				}
+				got[h.Name], _ = ioutil.ReadAll(tr)
+			}
+		}
+		var manifest ArchiveManifest
+		if err := json.Unmarshal(got["MANIFEST.json"], &manifest); err != nil || len(manifest.Entries) != 3 {
+			t.Errorf("archived manifest %s, error: %v", got["MANIFEST.json"], err)
+		}
+		delete(got, "MANIFEST.json")
+		if len(got) != len(want) {
+			t.Errorf("Object.PutArchive(%v) archived %v entries, want %v", format, len(got), len(want))
+		}
+		for k, v := range want {
+			if !bytes.Equal(got[k], v) {
+				t.Errorf("Object.PutArchive(%v) entry %v mismatch", format, k)
+			}
+		}
+	}
+}
+
+func TestObjectService_PutArchive_Failed(t *testing.T) {
+	setup()
+	defer teardown()
+
+	testArchiveServer(t, nil)
+	var aborted bool
+	mux.HandleFunc("/bundle.abort", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>archive</UploadId></InitiateMultipartUploadResult>`)
+		case http.MethodDelete:
+			aborted = true
+		}
+	})
+
+	_, _, err := client.Object.PutArchive(context.Background(), "bundle", "rar", nil)
+	if err == nil {
+		t.Errorf("Object.PutArchive with unsupported format expect error")
+	}
+	_, _, err = client.Object.PutArchive(context.Background(), "bundle", ArchiveZip, []ArchiveEntry{
+		{Name: "a", FilePath: "a", Object: "a"},
+	})
+	if err == nil {
+		t.Errorf("Object.PutArchive with multiple sources expect error")
+	}
+	_, _, err = client.Object.PutArchive(context.Background(), "bundle.abort", ArchiveTarGz, []ArchiveEntry{
+		{Name: "short.txt", Reader: strings.NewReader("short"), Size: 100},
+	})
+	if err == nil || !aborted {
+		t.Errorf("Object.PutArchive with short reader expect error and abort, error: %v, aborted: %v", err, aborted)
+	}
+}
@@ -0,0 +1,339 @@
+package cos
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"hash"
+	"hash/crc64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 客户端压缩时记录原始数据长度和 CRC64 的自定义头部
+const (
+	uncompressedLengthMeta = "x-cos-meta-uncompressed-length"
+	uncompressedCRC64Meta  = "x-cos-meta-uncompressed-crc64ecma"
+)
+
+// Compressor 是客户端压缩的编解码器，Encoding 的返回值会作为对象的 Content-Encoding
+type Compressor interface {
+	Encoding() string
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// GzipCompressor 是内置的 gzip 编解码器，Level 为 0 时使用 gzip.DefaultCompression
+type GzipCompressor struct {
+	Level int
+}
+
+func (c *GzipCompressor) Encoding() string {
+	return "gzip"
+}
+
+func (c *GzipCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	return gzip.NewWriterLevel(w, level)
+}
+
+func (c *GzipCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+var (
+	compressorsMu sync.RWMutex
+	compressors   = map[string]Compressor{
+		"gzip": &GzipCompressor{},
+	}
+)
+
+// RegisterCompressor 注册编解码器（例如 zstd、snappy），Get/Download 解压时按 Content-Encoding 查找
+func RegisterCompressor(c Compressor) {
+	compressorsMu.Lock()
+	defer compressorsMu.Unlock()
+	compressors[strings.ToLower(c.Encoding())] = c
+}
+
+func getCompressor(encoding string) Compressor {
+	compressorsMu.RLock()
+	defer compressorsMu.RUnlock()
+	return compressors[strings.ToLower(strings.TrimSpace(encoding))]
+}
+
+// compressTo 将 r 压缩写入 w，返回原始数据的长度和 CRC64
+func compressTo(w io.Writer, r io.Reader, c Compressor) (int64, uint64, error) {
+	crc := crc64.New(crc64.MakeTable(crc64.ECMA))
+	zw, err := c.NewWriter(w)
+	if err != nil {
+		return 0, 0, err
+	}
+	n, err := io.Copy(zw, io.TeeReader(r, crc))
+	if err != nil {
+		zw.Close()
+		return n, 0, err
+	}
+	if err = zw.Close(); err != nil {
+		return n, 0, err
+	}
+	return n, crc.Sum64(), nil
+}
+
+// setCompressedHeaders 设置压缩后对象的 Content-Encoding 以及原始数据的长度和 CRC64，
+// 调用方设置的 Content-Length/Content-MD5/SHA1 针对原始数据，压缩后不再适用。
+func setCompressedHeaders(opt *ObjectPutHeaderOptions, c Compressor, size int64, crc uint64) {
+	opt.ContentEncoding = c.Encoding()
+	opt.ContentLength = 0
+	opt.ContentMD5 = ""
+	opt.XCosContentSHA1 = ""
+	opt.Compressor = nil
+	if opt.XCosMetaXXX == nil {
+		opt.XCosMetaXXX = &http.Header{}
+	}
+	opt.XCosMetaXXX.Set(uncompressedLengthMeta, strconv.FormatInt(size, 10))
+	opt.XCosMetaXXX.Set(uncompressedCRC64Meta, strconv.FormatUint(crc, 10))
+}
+
+// 压缩后的数据超过该长度时转存到临时文件，避免大对象占用过多内存
+const compressMemoryLimit = 8 * 1024 * 1024
+
+// spillBuffer 在内存中缓存写入的数据，超过 limit 后转存到临时文件
+type spillBuffer struct {
+	limit int64
+	buf   bytes.Buffer
+	file  *os.File
+	size  int64
+}
+
+func (b *spillBuffer) Write(p []byte) (int, error) {
+	if b.file == nil && int64(b.buf.Len()+len(p)) > b.limit {
+		f, err := ioutil.TempFile("", "cos-compress-")
+		if err != nil {
+			return 0, err
+		}
+		b.file = f
+		if _, err = f.Write(b.buf.Bytes()); err != nil {
+			return 0, err
+		}
+		b.buf = bytes.Buffer{}
+	}
+	var n int
+	var err error
+	if b.file != nil {
+		n, err = b.file.Write(p)
+	} else {
+		n, err = b.buf.Write(p)
+	}
+	b.size += int64(n)
+	return n, err
+}
+
+// reader 返回可 Seek 的 reader，上传失败时可以重试。临时文件由 Close 关闭，避免被 http.Transport 提前关闭
+func (b *spillBuffer) reader() io.Reader {
+	if b.file == nil {
+		return bytes.NewReader(b.buf.Bytes())
+	}
+	return io.NewSectionReader(b.file, 0, b.size)
+}
+
+func (b *spillBuffer) Close() error {
+	if b.file == nil {
+		return nil
+	}
+	err := b.file.Close()
+	os.Remove(b.file.Name())
+	return err
+}
+
+// putCompressed 压缩 r 后上传，压缩后的数据超过 compressMemoryLimit 时转存到临时文件。
+// 压缩后的长度同样受简单上传 5GB 的限制，大文件请使用 Upload
+func (s *ObjectService) putCompressed(ctx context.Context, name string, r io.Reader, opt *ObjectPutOptions) (*Response, error) {
+	buf := &spillBuffer{limit: compressMemoryLimit}
+	defer buf.Close()
+	c := opt.Compressor
+	size, crc, err := compressTo(buf, r, c)
+	if err != nil {
+		return nil, err
+	}
+	if buf.size > singleUploadMaxLength {
+		return nil, fmt.Errorf("the compressed object size %v can not be larger than 5GB, use Upload instead", buf.size)
+	}
+	setCompressedHeaders(opt.ObjectPutHeaderOptions, c, size, crc)
+	opt.ContentLength = buf.size
+	return s.Put(ctx, name, buf.reader(), opt)
+}
+
+// uploadCompressed 将本地文件压缩到临时文件后分块上传，临时文件每次不同，因此不支持断点续传
+func (s *ObjectService) uploadCompressed(ctx context.Context, name string, filePath string, opt *MultiUploadOptions) (*CompleteMultipartUploadResult, *Response, error) {
+	fd, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer fd.Close()
+	tmp, err := ioutil.TempFile("", "cos-compress-")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.Remove(tmp.Name())
+	size, crc, err := compressTo(tmp, fd, opt.Compressor)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	uopt := *opt
+	uopt.Compressor = nil
+	uopt.CheckPoint = false
+	uopt.OptIni = CloneInitiateMultipartUploadOptions(opt.OptIni)
+	setCompressedHeaders(uopt.OptIni.ObjectPutHeaderOptions, opt.Compressor, size, crc)
+	return s.Upload(ctx, name, tmp.Name(), &uopt)
+}
+
+// decompressReader 解压响应 Body，读到 EOF 时校验原始数据的长度和 CRC64
+type decompressReader struct {
+	body    io.ReadCloser
+	c       Compressor
+	zr      io.ReadCloser
+	crc     hash.Hash64
+	n       int64
+	wantLen int64
+	wantCRC uint64
+	hasCRC  bool
+	err     error
+}
+
+func newDecompressReader(body io.ReadCloser, c Compressor, header http.Header) *decompressReader {
+	d := &decompressReader{
+		body:    body,
+		c:       c,
+		crc:     crc64.New(crc64.MakeTable(crc64.ECMA)),
+		wantLen: -1,
+	}
+	if v, err := strconv.ParseInt(header.Get(uncompressedLengthMeta), 10, 64); err == nil {
+		d.wantLen = v
+	}
+	if v, err := strconv.ParseUint(header.Get(uncompressedCRC64Meta), 10, 64); err == nil {
+		d.wantCRC, d.hasCRC = v, true
+	}
+	return d
+}
+
+func (d *decompressReader) Read(p []byte) (int, error) {
+	if d.err != nil {
+		return 0, d.err
+	}
+	if d.zr == nil {
+		d.zr, d.err = d.c.NewReader(d.body)
+		if d.err != nil {
+			return 0, d.err
+		}
+	}
+	n, err := d.zr.Read(p)
+	d.crc.Write(p[:n])
+	d.n += int64(n)
+	if err == io.EOF {
+		if d.wantLen >= 0 && d.n != d.wantLen {
+			err = fmt.Errorf("decompressed length mismatch, want:%v, got:%v", d.wantLen, d.n)
+		} else if d.hasCRC && d.crc.Sum64() != d.wantCRC {
+			err = fmt.Errorf("decompressed verification failed, want:%v, return:%v", d.wantCRC, d.crc.Sum64())
+		}
+	}
+	if err != nil {
+		d.err = err
+	}
+	return n, err
+}
+
+func (d *decompressReader) Close() error {
+	if d.zr != nil {
+		d.zr.Close()
+	}
+	return d.body.Close()
+}
+
+// decompressResponse 按 Content-Encoding 解压响应，未注册的编码保持原样
+func decompressResponse(resp *Response) {
+	c := getCompressor(resp.Header.Get("Content-Encoding"))
+	if c == nil {
+		return
+	}
+	d := newDecompressReader(resp.Body, c, resp.Header)
+	resp.Body = d
+	resp.Uncompressed = true
+	resp.ContentLength = d.wantLen
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	if d.wantLen >= 0 {
+		resp.Header.Set("Content-Length", strconv.FormatInt(d.wantLen, 10))
+	}
+}
+
+// withIdentityEncoding 显式指定 Accept-Encoding，避免 http.Transport 自动解压 gzip 对象
+func withIdentityEncoding(opt *ObjectGetOptions) *ObjectGetOptions {
+	res := CloneObjectGetOptions(opt)
+	res.Decompress = false
+	if res.XOptionHeader == nil {
+		res.XOptionHeader = &http.Header{}
+	}
+	if res.XOptionHeader.Get("Accept-Encoding") == "" {
+		res.XOptionHeader.Set("Accept-Encoding", "identity")
+	}
+	return res
+}
+
+// downloadDecompressed 下载压缩后的数据，完成后在本地解压替换目标文件
+func (s *ObjectService) downloadDecompressed(ctx context.Context, name string, filePath string, opt *MultiDownloadOptions, id ...string) (*Response, error) {
+	dopt := *opt
+	dopt.Decompress = false
+	dopt.Opt = withIdentityEncoding(opt.Opt)
+	resp, err := s.Download(ctx, name, filePath, &dopt, id...)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	c := getCompressor(resp.Header.Get("Content-Encoding"))
+	if c == nil {
+		return resp, nil
+	}
+	return resp, decompressFile(filePath, c, resp.Header)
+}
+
+func decompressFile(filePath string, c Compressor, header http.Header) error {
+	src, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmp, err := ioutil.TempFile(filepath.Dir(filePath), filepath.Base(filePath)+".decompress-")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tmp, newDecompressReader(ioutil.NopCloser(src), c, header))
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0660)
+	}
+	if err == nil {
+		src.Close()
+		err = os.Rename(tmp.Name(), filePath)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
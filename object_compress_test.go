@@ -0,0 +1,297 @@
+package cos
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"hash/crc64"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testCompressedObject struct {
+	mu     sync.Mutex
+	body   []byte
+	header http.Header
+}
+
+func (o *testCompressedObject) handle(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		if r.Method == http.MethodPut {
+			o.body, _ = ioutil.ReadAll(r.Body)
+			o.header = r.Header
+			w.Header().Set("x-cos-hash-crc64ecma", strconv.FormatUint(crc64.Checksum(o.body, crc64.MakeTable(crc64.ECMA)), 10))
+			return
+		}
+		if r.Method == http.MethodGet && r.Header.Get("Accept-Encoding") != "identity" {
+			t.Errorf("Accept-Encoding: %v, want identity", r.Header.Get("Accept-Encoding"))
+		}
+		for _, k := range []string{"Content-Encoding", uncompressedLengthMeta, uncompressedCRC64Meta} {
+			w.Header().Set(k, o.header.Get(k))
+		}
+		w.Header().Set("x-cos-hash-crc64ecma", strconv.FormatUint(crc64.Checksum(o.body, crc64.MakeTable(crc64.ECMA)), 10))
+		// 设置了 Content-Encoding 时 http.ServeContent 不会返回 Content-Length
+		if r.Method == http.MethodHead {
+			w.Header().Set("Content-Length", strconv.Itoa(len(o.body)))
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(o.body))
+	}
+}
+
+func TestObjectService_PutGet_Compressor(t *testing.T) {
+	setup()
+	defer teardown()
+
+	obj := &testCompressedObject{}
+	mux.HandleFunc("/test.json", obj.handle(t))
+
+	data := []byte(strings.Repeat(`{"metric":"cpu","value":42}`+"\n", 1000))
+	opt := &ObjectPutOptions{
+		ObjectPutHeaderOptions: &ObjectPutHeaderOptions{
+			ContentType:   "application/json",
+			ContentLength: int64(len(data)),
+			Compressor:    &GzipCompressor{Level: gzip.BestCompression},
+		},
+	}
+	_, err := client.Object.Put(context.Background(), "test.json", bytes.NewReader(data), opt)
+	if err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+	if opt.ContentLength != int64(len(data)) || opt.ContentEncoding != "" {
+		t.Errorf("Object.Put modified the caller's options")
+	}
+	if got := obj.header.Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("Content-Encoding: %v, want gzip", got)
+	}
+	if got := obj.header.Get(uncompressedLengthMeta); got != strconv.Itoa(len(data)) {
+		t.Errorf("%v: %v, want %v", uncompressedLengthMeta, got, len(data))
+	}
+	if len(obj.body) >= len(data)/10 {
+		t.Errorf("compressed size %v is not smaller than %v", len(obj.body), len(data)/10)
+	}
+
+	resp, err := client.Object.Get(context.Background(), "test.json", &ObjectGetOptions{Decompress: true})
+	if err != nil {
+		t.Fatalf("Object.Get returned error: %v", err)
+	}
+	got, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("read decompressed body returned error: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("Object.Get decompressed data mismatch")
+	}
+	if !resp.Uncompressed || resp.ContentLength != int64(len(data)) {
+		t.Errorf("Object.Get Uncompressed: %v, ContentLength: %v", resp.Uncompressed, resp.ContentLength)
+	}
+
+	_, err = client.Object.Get(context.Background(), "test.json", &ObjectGetOptions{Decompress: true, Range: "bytes=0-1"})
+	if err == nil {
+		t.Errorf("Object.Get with Decompress and Range expect error")
+	}
+
+	// 原始数据的 CRC64 不一致
+	obj.header.Set(uncompressedCRC64Meta, "1")
+	resp, err = client.Object.Get(context.Background(), "test.json", &ObjectGetOptions{Decompress: true})
+	if err != nil {
+		t.Fatalf("Object.Get returned error: %v", err)
+	}
+	_, err = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err == nil {
+		t.Errorf("Object.Get with mismatched crc64 expect error")
+	}
+}
+
+func TestObjectService_Put_CompressorSpill(t *testing.T) {
+	setup()
+	defer teardown()
+
+	obj := &testCompressedObject{}
+	handle := obj.handle(t)
+	var puts int
+	mux.HandleFunc("/test.bin", func(w http.ResponseWriter, r *http.Request) {
+		// 第一次上传失败，重试时从临时文件重新读取
+		if r.Method == http.MethodPut {
+			if puts++; puts == 1 {
+				ioutil.ReadAll(r.Body)
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			if r.ContentLength <= compressMemoryLimit {
+				t.Errorf("Content-Length: %v", r.ContentLength)
+			}
+		}
+		handle(w, r)
+	})
+
+	// 随机数据几乎不可压缩，压缩后超过 compressMemoryLimit
+	data := make([]byte, compressMemoryLimit+1024*1024)
+	rand.Read(data)
+	opt := &ObjectPutOptions{
+		ObjectPutHeaderOptions: &ObjectPutHeaderOptions{Compressor: &GzipCompressor{}},
+	}
+	if _, err := client.Object.Put(context.Background(), "test.bin", bytes.NewReader(data), opt); err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+	if puts != 2 {
+		t.Errorf("Object.Put sent %v requests, want 2", puts)
+	}
+	resp, err := client.Object.Get(context.Background(), "test.bin", &ObjectGetOptions{Decompress: true})
+	if err != nil {
+		t.Fatalf("Object.Get returned error: %v", err)
+	}
+	got, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Object.Get decompressed data mismatch, error: %v", err)
+	}
+}
+
+func TestSpillBuffer(t *testing.T) {
+	b := &spillBuffer{limit: 4}
+	b.Write([]byte("abc"))
+	if b.file != nil {
+		t.Fatalf("spillBuffer spilled before limit")
+	}
+	b.Write([]byte("defg"))
+	if b.file == nil || b.size != 7 {
+		t.Fatalf("spillBuffer did not spill, size: %v", b.size)
+	}
+	got, _ := ioutil.ReadAll(b.reader())
+	if string(got) != "abcdefg" {
+		t.Errorf("spillBuffer reader returned %q", got)
+	}
+	name := b.file.Name()
+	b.Close()
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("temp file %v is not removed", name)
+	}
+}
+
+func TestObjectService_UploadDownload_Compressor(t *testing.T) {
+	setup()
+	defer teardown()
+
+	obj := &testCompressedObject{}
+	mux.HandleFunc("/test.log", obj.handle(t))
+
+	// 随机数据不可压缩，压缩后仍大于 1MB，下载时会分块
+	data := make([]byte, 1024*1024*2)
+	rand.Read(data)
+	data = append(data, strings.Repeat("GET /index.html 200\n", 1024*100)...)
+	src := "tmpfile" + time.Now().Format(time.RFC3339)
+	dst := src + ".download"
+	ioutil.WriteFile(src, data, 0644)
+	defer os.Remove(src)
+	defer os.Remove(dst)
+
+	_, _, err := client.Object.Upload(context.Background(), "test.log", src, &MultiUploadOptions{
+		Compressor: &GzipCompressor{},
+	})
+	if err != nil {
+		t.Fatalf("Object.Upload returned error: %v", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(obj.body))
+	if err != nil {
+		t.Fatalf("gzip.NewReader returned error: %v", err)
+	}
+	got, _ := ioutil.ReadAll(zr)
+	if !bytes.Equal(got, data) {
+		t.Errorf("Object.Upload uploaded data mismatch")
+	}
+
+	for _, partSize := range []int64{1, 10} {
+		_, err = client.Object.Download(context.Background(), "test.log", dst, &MultiDownloadOptions{
+			PartSize:       partSize,
+			ThreadPoolSize: 2,
+			Decompress:     true,
+		})
+		if err != nil {
+			t.Fatalf("Object.Download returned error: %v", err)
+		}
+		got, _ = ioutil.ReadFile(dst)
+		if !bytes.Equal(got, data) {
+			t.Errorf("Object.Download decompressed data mismatch")
+		}
+	}
+
+	obj.header.Set(uncompressedLengthMeta, "1")
+	_, err = client.Object.Download(context.Background(), "test.log", dst, &MultiDownloadOptions{
+		PartSize:   1,
+		Decompress: true,
+	})
+	if err == nil {
+		t.Errorf("Object.Download with mismatched length expect error")
+	}
+}
+
+type testUpperCompressor struct{}
+
+func (testUpperCompressor) Encoding() string { return "x-upper" }
+
+func (testUpperCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return &testUpperWriter{w}, nil
+}
+
+func (testUpperCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(strings.NewReader(strings.ToLower(string(b)))), nil
+}
+
+type testUpperWriter struct {
+	w io.Writer
+}
+
+func (u *testUpperWriter) Write(p []byte) (int, error) {
+	return u.w.Write(bytes.ToUpper(p))
+}
+
+func (u *testUpperWriter) Close() error {
+	return nil
+}
+
+func TestRegisterCompressor(t *testing.T) {
+	setup()
+	defer teardown()
+
+	RegisterCompressor(testUpperCompressor{})
+	obj := &testCompressedObject{}
+	mux.HandleFunc("/test.txt", obj.handle(t))
+
+	opt := &ObjectPutOptions{
+		ObjectPutHeaderOptions: &ObjectPutHeaderOptions{
+			Compressor: testUpperCompressor{},
+		},
+	}
+	_, err := client.Object.Put(context.Background(), "test.txt", strings.NewReader("hello"), opt)
+	if err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+	if string(obj.body) != "HELLO" || obj.header.Get("Content-Encoding") != "x-upper" {
+		t.Errorf("Object.Put body: %s, Content-Encoding: %v", obj.body, obj.header.Get("Content-Encoding"))
+	}
+	resp, err := client.Object.Get(context.Background(), "test.txt", &ObjectGetOptions{Decompress: true})
+	if err != nil {
+		t.Fatalf("Object.Get returned error: %v", err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil || string(b) != "hello" {
+		t.Errorf("Object.Get returned %s, error: %v", b, err)
+	}
+}
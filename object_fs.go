@@ -0,0 +1,434 @@
+package cos
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FS 将存储桶中 prefix 下的对象作为只读的 fs.FS，对象键中的 / 作为目录分隔符。
+//
+// 目录通过 Delimiter 为 / 的 Get Bucket 模拟，Open 返回的文件支持 Seek 与 ReadAt，
+// 数据通过范围下载按需读取。可以用于 template.ParseFS、http.FS、fs.WalkDir 等。
+type FS struct {
+	client *Client
+	prefix string
+	ctx    context.Context
+}
+
+var (
+	_ fs.FS         = (*FS)(nil)
+	_ fs.ReadDirFS  = (*FS)(nil)
+	_ fs.StatFS     = (*FS)(nil)
+	_ fs.ReadFileFS = (*FS)(nil)
+	_ fs.SubFS      = (*FS)(nil)
+)
+
+// NewFS 创建 prefix 下的 FS，prefix 为空时为整个存储桶
+func NewFS(client *Client, prefix string) *FS {
+	prefix = strings.TrimLeft(prefix, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &FS{
+		client: client,
+		prefix: prefix,
+		ctx:    context.Background(),
+	}
+}
+
+// WithContext 返回使用 ctx 发送请求的 FS
+func (f *FS) WithContext(ctx context.Context) *FS {
+	nf := *f
+	nf.ctx = ctx
+	return &nf
+}
+
+func (f *FS) key(name string) string {
+	if name == "." {
+		return f.prefix
+	}
+	return f.prefix + name
+}
+
+func fsPathError(op, name string, err error) error {
+	if IsNotFoundError(err) {
+		err = fs.ErrNotExist
+	} else if e, ok := IsCOSError(err); ok && e.Response != nil && e.Response.StatusCode == http.StatusForbidden {
+		err = fs.ErrPermission
+	}
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// fsFileInfo 实现 fs.FileInfo 与 fs.DirEntry
+type fsFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	isDir   bool
+	sys     interface{}
+}
+
+func (fi *fsFileInfo) Name() string       { return fi.name }
+func (fi *fsFileInfo) Size() int64        { return fi.size }
+func (fi *fsFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *fsFileInfo) IsDir() bool        { return fi.isDir }
+func (fi *fsFileInfo) Sys() interface{}   { return fi.sys }
+func (fi *fsFileInfo) Mode() fs.FileMode {
+	if fi.isDir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+func (fi *fsFileInfo) Type() fs.FileMode          { return fi.Mode().Type() }
+func (fi *fsFileInfo) Info() (fs.FileInfo, error) { return fi, nil }
+
+func fsDirInfo(name string) *fsFileInfo {
+	return &fsFileInfo{name: path.Base(name), isDir: true}
+}
+
+// Stat 返回文件或者目录的信息，文件的 Sys 为 *ObjectMeta
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	fi, err := f.stat("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return fi, nil
+}
+
+func (f *FS) stat(op, name string) (*fsFileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return fsDirInfo(name), nil
+	}
+	meta, _, err := f.client.Object.Stat(f.ctx, f.key(name), nil)
+	if err == nil {
+		return &fsFileInfo{
+			name:    path.Base(name),
+			size:    meta.ContentLength,
+			modTime: meta.LastModified.Truncate(time.Second),
+			sys:     meta,
+		}, nil
+	}
+	if !IsNotFoundError(err) {
+		return nil, fsPathError(op, name, err)
+	}
+	// 对象不存在时，判断是否为目录
+	res, _, err := f.client.Bucket.Get(f.ctx, &BucketGetOptions{
+		Prefix:    f.key(name) + "/",
+		Delimiter: "/",
+		MaxKeys:   1,
+	})
+	if err != nil {
+		return nil, fsPathError(op, name, err)
+	}
+	if len(res.Contents) == 0 && len(res.CommonPrefixes) == 0 {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return fsDirInfo(name), nil
+}
+
+// Open 打开文件或者目录
+func (f *FS) Open(name string) (fs.File, error) {
+	fi, err := f.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if fi.isDir {
+		return &fsDir{fs: f, name: name, info: fi}, nil
+	}
+	return &fsFile{fs: f, name: name, info: fi}, nil
+}
+
+// ReadFile 读取整个文件
+func (f *FS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: errors.New("is a directory")}
+	}
+	resp, err := f.client.Object.Get(f.ctx, f.key(name), nil)
+	if err != nil {
+		return nil, fsPathError("readfile", name, err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: err}
+	}
+	return data, nil
+}
+
+// ReadDir 列出目录，结果按名称排序
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	d := &fsDir{fs: f, name: name}
+	entries, err := d.ReadDir(-1)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 && name != "." {
+		// 空的结果无法区分空目录与不存在的目录
+		fi, err := f.stat("readdir", name)
+		if err != nil {
+			return nil, err
+		}
+		if !fi.isDir {
+			return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// Sub 返回 dir 下的 FS
+func (f *FS) Sub(dir string) (fs.FS, error) {
+	if !fs.ValidPath(dir) {
+		return nil, &fs.PathError{Op: "sub", Path: dir, Err: fs.ErrInvalid}
+	}
+	if dir == "." {
+		return f, nil
+	}
+	nf := *f
+	nf.prefix = f.prefix + dir + "/"
+	return &nf, nil
+}
+
+// fsFile 是只读文件，数据通过范围下载读取
+type fsFile struct {
+	fs     *FS
+	name   string
+	info   *fsFileInfo
+	offset int64
+	body   io.ReadCloser
+	closed bool
+}
+
+func (f *fsFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+// getOptions 返回范围下载的选项，使用 If-Match 保证读取的是 Stat 时的对象
+func (f *fsFile) getOptions(start, end int64) *ObjectGetOptions {
+	opt := &ObjectGetOptions{
+		Range: FormatRangeOptions(&RangeOptions{HasStart: true, HasEnd: end >= 0, Start: start, End: end}),
+	}
+	if meta, ok := f.info.sys.(*ObjectMeta); ok && meta.ETag != "" {
+		opt.XOptionHeader = &http.Header{}
+		opt.XOptionHeader.Set("If-Match", meta.ETag)
+	}
+	return opt
+}
+
+func (f *fsFile) Read(p []byte) (int, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrClosed}
+	}
+	if f.offset >= f.info.size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if f.body == nil {
+		resp, err := f.fs.client.Object.Get(f.fs.ctx, f.fs.key(f.name), f.getOptions(f.offset, -1))
+		if err != nil {
+			return 0, fsPathError("read", f.name, err)
+		}
+		f.body = resp.Body
+	}
+	n, err := f.body.Read(p)
+	f.offset += int64(n)
+	if err == io.EOF && f.offset < f.info.size {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil && err != io.EOF {
+		f.body.Close()
+		f.body = nil
+	}
+	return n, err
+}
+
+func (f *fsFile) Seek(offset int64, whence int) (int64, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrClosed}
+	}
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.info.size
+	default:
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+	if offset != f.offset && f.body != nil {
+		f.body.Close()
+		f.body = nil
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *fsFile) ReadAt(p []byte, off int64) (int, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrClosed}
+	}
+	if off < 0 {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrInvalid}
+	}
+	if off >= f.info.size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	end := off + int64(len(p)) - 1
+	if end >= f.info.size {
+		end = f.info.size - 1
+	}
+	resp, err := f.fs.client.Object.Get(f.fs.ctx, f.fs.key(f.name), f.getOptions(off, end))
+	if err != nil {
+		return 0, fsPathError("read", f.name, err)
+	}
+	defer resp.Body.Close()
+	n, err := io.ReadFull(resp.Body, p[:end-off+1])
+	if err != nil {
+		return n, &fs.PathError{Op: "read", Path: f.name, Err: err}
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (f *fsFile) Close() error {
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.name, Err: fs.ErrClosed}
+	}
+	f.closed = true
+	if f.body != nil {
+		return f.body.Close()
+	}
+	return nil
+}
+
+// fsDir 是目录，ReadDir 分页列出目录下的文件与子目录
+type fsDir struct {
+	fs        *FS
+	name      string
+	info      *fsFileInfo
+	marker    string
+	truncated bool
+	started   bool
+	entries   []fs.DirEntry
+	closed    bool
+}
+
+func (d *fsDir) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d *fsDir) Read(p []byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+func (d *fsDir) Close() error {
+	if d.closed {
+		return &fs.PathError{Op: "close", Path: d.name, Err: fs.ErrClosed}
+	}
+	d.closed = true
+	return nil
+}
+
+// list 列出下一页
+func (d *fsDir) list() error {
+	prefix := d.fs.key(d.name)
+	if d.name != "." {
+		prefix += "/"
+	}
+	res, _, err := d.fs.client.Bucket.Get(d.fs.ctx, &BucketGetOptions{
+		Prefix:       prefix,
+		Delimiter:    "/",
+		EncodingType: "url",
+		Marker:       d.marker,
+		MaxKeys:      1000,
+	})
+	if err != nil {
+		return fsPathError("readdir", d.name, err)
+	}
+	d.started = true
+	d.truncated = res.IsTruncated
+	d.marker, _ = decodeURIComponent(res.NextMarker)
+	var entries []fs.DirEntry
+	for _, p := range res.CommonPrefixes {
+		p, _ = decodeURIComponent(p)
+		name := strings.TrimSuffix(strings.TrimPrefix(p, prefix), "/")
+		if fsValidName(name) {
+			entries = append(entries, fsDirInfo(name))
+		}
+	}
+	for _, obj := range res.Contents {
+		key, _ := decodeURIComponent(obj.Key)
+		name := strings.TrimPrefix(key, prefix)
+		if !fsValidName(name) {
+			// 目录本身的占位对象，例如 "dir/"
+			continue
+		}
+		modTime, _ := ParseObjectTime(obj.LastModified)
+		entries = append(entries, &fsFileInfo{
+			name:    name,
+			size:    obj.Size,
+			modTime: modTime.Truncate(time.Second),
+			sys:     obj,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	d.entries = append(d.entries, entries...)
+	return nil
+}
+
+func fsValidName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.Contains(name, "/")
+}
+
+// ReadDir 见 fs.ReadDirFile
+func (d *fsDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d.closed {
+		return nil, &fs.PathError{Op: "readdir", Path: d.name, Err: fs.ErrClosed}
+	}
+	for (n <= 0 || len(d.entries) < n) && (!d.started || d.truncated) {
+		if err := d.list(); err != nil {
+			return nil, err
+		}
+	}
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	entries := d.entries[:n:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
@@ -0,0 +1,207 @@
+package cos
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"text/template"
+	"time"
+)
+
+// testFSBucket 是内存中的存储桶，支持 Head/Get Object 以及带 Delimiter 的 Get Bucket
+func testFSBucket(t *testing.T, objects map[string]string) {
+	modTime := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			key := strings.TrimPrefix(r.URL.Path, "/")
+			data, ok := objects[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				if r.Method != http.MethodHead {
+					w.Write([]byte("<Error><Code>NoSuchKey</Code></Error>"))
+				}
+				return
+			}
+			etag := `"` + key + `"`
+			if m := r.Header.Get("If-Match"); m != "" && m != etag {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			w.Header().Set("ETag", etag)
+			http.ServeContent(w, r, "", modTime, strings.NewReader(data))
+			return
+		}
+		testMethod(t, r, http.MethodGet)
+		q := r.URL.Query()
+		prefix, delimiter, marker := q.Get("prefix"), q.Get("delimiter"), q.Get("marker")
+		var keys []string
+		for k := range objects {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		res := BucketGetResult{MaxKeys: 2}
+		if q.Get("max-keys") == "1" {
+			res.MaxKeys = 1
+		}
+		seen := make(map[string]bool)
+		count := 0
+		for _, k := range keys {
+			if !strings.HasPrefix(k, prefix) || k <= marker {
+				continue
+			}
+			if count == res.MaxKeys {
+				res.IsTruncated = true
+				break
+			}
+			rest := k[len(prefix):]
+			if i := strings.Index(rest, delimiter); delimiter != "" && i >= 0 {
+				p := prefix + rest[:i+1]
+				if seen[p] {
+					continue
+				}
+				seen[p] = true
+				res.CommonPrefixes = append(res.CommonPrefixes, encodeURIComponent(p))
+				res.NextMarker = encodeURIComponent(p + "\xff")
+			} else {
+				res.Contents = append(res.Contents, Object{
+					Key:          encodeURIComponent(k),
+					Size:         int64(len(objects[k])),
+					LastModified: modTime.Format("2006-01-02T15:04:05.000Z"),
+				})
+				res.NextMarker = encodeURIComponent(k)
+			}
+			count++
+		}
+		xml.NewEncoder(w).Encode(res)
+	})
+}
+
+func TestFS(t *testing.T) {
+	setup()
+	defer teardown()
+
+	objects := map[string]string{
+		"www/index.html":          "<html>{{template \"footer\"}}</html>",
+		"www/footer.tmpl":         `{{define "footer"}}footer{{end}}`,
+		"www/css/a.css":           "body{}",
+		"www/css/b.css":           "",
+		"www/js/":                 "",
+		"www/js/lib/app.js":       strings.Repeat("js", 1000),
+		"www/empty/":              "",
+		"www/docs/readme.md":      "# readme",
+		"other/secret.txt":        "secret",
+		"www/docs/sub/deep/x.txt": "x",
+	}
+	testFSBucket(t, objects)
+
+	fsys := NewFS(client, "www")
+	if err := fstest.TestFS(fsys, "index.html", "css/a.css", "css/b.css", "js/lib/app.js", "docs/sub/deep/x.txt", "empty"); err != nil {
+		t.Fatalf("fstest.TestFS returned error: %v", err)
+	}
+	sub, err := fs.Sub(fsys, "docs")
+	if err != nil {
+		t.Fatalf("fs.Sub returned error: %v", err)
+	}
+	if err := fstest.TestFS(sub, "readme.md", "sub/deep/x.txt"); err != nil {
+		t.Fatalf("fstest.TestFS returned error: %v", err)
+	}
+
+	tmpl, err := template.ParseFS(fsys, "*.html", "*.tmpl")
+	if err != nil {
+		t.Fatalf("template.ParseFS returned error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err = tmpl.ExecuteTemplate(&buf, "index.html", nil); err != nil || buf.String() != "<html>footer</html>" {
+		t.Errorf("template returned %q, err: %v", buf.String(), err)
+	}
+
+	var walked []string
+	fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		walked = append(walked, path)
+		return nil
+	})
+	want := ". css css/a.css css/b.css docs docs/readme.md docs/sub docs/sub/deep docs/sub/deep/x.txt empty footer.tmpl index.html js js/lib js/lib/app.js"
+	if strings.Join(walked, " ") != want {
+		t.Errorf("fs.WalkDir returned %v", walked)
+	}
+
+	if _, err = fsys.Open("secret.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("FS.Open expect ErrNotExist, got: %v", err)
+	}
+	if _, err = fsys.Open("../other/secret.txt"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("FS.Open expect ErrInvalid, got: %v", err)
+	}
+	if _, err = fsys.ReadDir("notexist"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("FS.ReadDir expect ErrNotExist, got: %v", err)
+	}
+	if _, err = fsys.ReadFile("notexist"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("FS.ReadFile expect ErrNotExist, got: %v", err)
+	}
+}
+
+func TestFS_File(t *testing.T) {
+	setup()
+	defer teardown()
+
+	data := "0123456789abcdefghij"
+	objects := map[string]string{"data.bin": data}
+	testFSBucket(t, objects)
+
+	fsys := NewFS(client, "")
+	f, err := fsys.Open("data.bin")
+	if err != nil {
+		t.Fatalf("FS.Open returned error: %v", err)
+	}
+	fi, _ := f.Stat()
+	meta, ok := fi.Sys().(*ObjectMeta)
+	if fi.Size() != int64(len(data)) || !ok || meta.ETag != `"data.bin"` {
+		t.Errorf("File.Stat returned %+v", fi)
+	}
+	rs := f.(io.ReadSeeker)
+	if _, err = rs.Seek(15, io.SeekStart); err != nil {
+		t.Fatalf("File.Seek returned error: %v", err)
+	}
+	b, _ := ioutil.ReadAll(rs)
+	if string(b) != data[15:] {
+		t.Errorf("File.Read returned %q", b)
+	}
+	if pos, _ := rs.Seek(-5, io.SeekCurrent); pos != 15 {
+		t.Errorf("File.Seek returned %v", pos)
+	}
+	p := make([]byte, 4)
+	if n, err := f.(io.ReaderAt).ReadAt(p, 2); n != 4 || err != nil || string(p) != "2345" {
+		t.Errorf("File.ReadAt returned %v, %v, %q", n, err, p)
+	}
+	if n, err := f.(io.ReaderAt).ReadAt(p, 18); n != 2 || err != io.EOF || string(p[:n]) != "ij" {
+		t.Errorf("File.ReadAt returned %v, %v, %q", n, err, p)
+	}
+	if _, err = rs.Seek(-1, io.SeekStart); err == nil {
+		t.Errorf("File.Seek expect error")
+	}
+
+	// 对象在打开后被删除
+	delete(objects, "data.bin")
+	if _, err = rs.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("File.Seek returned error: %v", err)
+	}
+	if _, err = rs.Read(p); err == nil {
+		t.Errorf("File.Read expect error")
+	}
+	if err = f.Close(); err != nil {
+		t.Errorf("File.Close returned error: %v", err)
+	}
+	if _, err = rs.Read(p); !errors.Is(err, fs.ErrClosed) {
+		t.Errorf("File.Read expect ErrClosed, got: %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package cos
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// objectHandlerHeaders 是从 COS 响应透传给客户端的头部
+var objectHandlerHeaders = []string{
+	"Content-Type",
+	"Content-Length",
+	"Content-Range",
+	"Content-Encoding",
+	"Content-Disposition",
+	"Content-Language",
+	"Cache-Control",
+	"Expires",
+	"ETag",
+	"Last-Modified",
+	"Accept-Ranges",
+}
+
+// objectHandlerConditionalHeaders 是透传给 COS 的条件请求头部
+var objectHandlerConditionalHeaders = []string{
+	"If-None-Match",
+	"If-Match",
+	"If-Unmodified-Since",
+}
+
+// ObjectHandler 是将 GET/HEAD 请求代理到存储桶对象的 http.Handler。
+//
+// Range、If-None-Match、If-Modified-Since 等请求头部透传给 Get/Head，响应体流式转发，
+// ETag、Content-Type、Cache-Control 等响应头部原样返回。COS 的错误映射为对应的 HTTP 状态码：
+// 不存在返回 404，无权限返回 403，条件不满足返回 304/412，其他错误返回 502。
+type ObjectHandler struct {
+	Client *Client
+	// 将请求映射为对象键，ok 为 false 时返回 404。默认为去掉开头 / 的 URL Path
+	KeyMapper func(r *http.Request) (key string, ok bool)
+	// 请求鉴权，返回错误时返回 403
+	Authorize func(r *http.Request, key string) error
+	// 设置请求 COS 时的额外选项，例如 SSE-C 头部
+	GetOptions func(r *http.Request, key string, opt *ObjectGetOptions)
+	// 对象没有 Cache-Control 时使用的默认值
+	CacheControl string
+	// 自定义错误响应，默认使用 http.Error 返回状态码对应的文本
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, status int, err error)
+}
+
+// NewObjectHandler 创建代理整个存储桶的 ObjectHandler，可以结合 http.StripPrefix 使用
+func NewObjectHandler(client *Client) *ObjectHandler {
+	return &ObjectHandler{Client: client}
+}
+
+func (h *ObjectHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		h.error(w, r, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	key, ok := h.key(r)
+	if !ok || key == "" {
+		h.error(w, r, http.StatusNotFound, errors.New("object key is empty"))
+		return
+	}
+	if h.Authorize != nil {
+		if err := h.Authorize(r, key); err != nil {
+			h.error(w, r, http.StatusForbidden, err)
+			return
+		}
+	}
+
+	opt := &ObjectGetOptions{
+		IfModifiedSince: r.Header.Get("If-Modified-Since"),
+		XOptionHeader:   &http.Header{},
+	}
+	for _, k := range objectHandlerConditionalHeaders {
+		if v := r.Header.Get(k); v != "" {
+			opt.XOptionHeader.Set(k, v)
+		}
+	}
+	if h.GetOptions != nil {
+		h.GetOptions(r, key, opt)
+	}
+
+	var resp *Response
+	var err error
+	if r.Method == http.MethodHead {
+		resp, err = h.Client.Object.Head(r.Context(), key, &ObjectHeadOptions{
+			IfModifiedSince:       opt.IfModifiedSince,
+			XCosSSECustomerAglo:   opt.XCosSSECustomerAglo,
+			XCosSSECustomerKey:    opt.XCosSSECustomerKey,
+			XCosSSECustomerKeyMD5: opt.XCosSSECustomerKeyMD5,
+			XOptionHeader:         opt.XOptionHeader,
+		})
+	} else {
+		opt.Range = r.Header.Get("Range")
+		resp, err = h.Client.Object.Get(r.Context(), key, opt)
+	}
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	h.copyHeader(w, resp)
+	w.WriteHeader(resp.StatusCode)
+	if r.Method == http.MethodHead {
+		return
+	}
+	// 客户端断开时 Copy 返回错误，此时已经无法返回错误响应
+	io.Copy(w, resp.Body)
+}
+
+func (h *ObjectHandler) key(r *http.Request) (string, bool) {
+	if h.KeyMapper != nil {
+		return h.KeyMapper(r)
+	}
+	return strings.TrimPrefix(r.URL.Path, "/"), true
+}
+
+func (h *ObjectHandler) copyHeader(w http.ResponseWriter, resp *Response) {
+	header := w.Header()
+	for _, k := range objectHandlerHeaders {
+		if v := resp.Header.Get(k); v != "" {
+			header.Set(k, v)
+		}
+	}
+	if header.Get("Cache-Control") == "" && h.CacheControl != "" {
+		header.Set("Cache-Control", h.CacheControl)
+	}
+	if header.Get("Accept-Ranges") == "" {
+		header.Set("Accept-Ranges", "bytes")
+	}
+}
+
+func (h *ObjectHandler) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, context.Canceled) {
+		// 客户端已断开
+		return
+	}
+	status := http.StatusBadGateway
+	if e, ok := IsCOSError(err); ok && e.Response != nil {
+		switch code := e.Response.StatusCode; code {
+		case http.StatusNotModified:
+			h.copyHeader(w, &Response{Response: e.Response})
+			w.Header().Del("Content-Length")
+			w.WriteHeader(code)
+			return
+		case http.StatusRequestedRangeNotSatisfiable:
+			if v := e.Response.Header.Get("Content-Range"); v != "" {
+				w.Header().Set("Content-Range", v)
+			}
+			status = code
+		case http.StatusNotFound, http.StatusForbidden, http.StatusPreconditionFailed, http.StatusBadRequest:
+			status = code
+		case http.StatusUnauthorized:
+			status = http.StatusForbidden
+		}
+	}
+	h.error(w, r, status, err)
+}
+
+func (h *ObjectHandler) error(w http.ResponseWriter, r *http.Request, status int, err error) {
+	if h.ErrorHandler != nil {
+		h.ErrorHandler(w, r, status, err)
+		return
+	}
+	http.Error(w, http.StatusText(status), status)
+}
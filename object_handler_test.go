@@ -0,0 +1,155 @@
+package cos
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestObjectHandler(t *testing.T) {
+	setup()
+	defer teardown()
+
+	modTime := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	content := "hello, object handler"
+	var requests int32
+	mux.HandleFunc("/static/", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		switch r.URL.Path {
+		case "/static/hello.txt":
+			w.Header().Set("ETag", `"etag"`)
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("x-cos-meta-secret", "secret")
+			http.ServeContent(w, r, "", modTime, strings.NewReader(content))
+		case "/static/nocache.txt":
+			http.ServeContent(w, r, "", modTime, strings.NewReader(content))
+		case "/static/private.txt":
+			w.WriteHeader(http.StatusForbidden)
+		case "/static/broken.txt":
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	h := NewObjectHandler(client)
+	h.KeyMapper = func(r *http.Request) (string, bool) {
+		if strings.Contains(r.URL.Path, "..") {
+			return "", false
+		}
+		return "static" + r.URL.Path, true
+	}
+	h.Authorize = func(r *http.Request, key string) error {
+		if r.Header.Get("X-Token") == "deny" {
+			return errors.New("denied")
+		}
+		return nil
+	}
+	h.CacheControl = "no-cache"
+
+	serve := func(method, path string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(http.MethodGet, "/hello.txt", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != content {
+		t.Fatalf("ObjectHandler returned %v, %q", rec.Code, rec.Body.String())
+	}
+	for k, v := range map[string]string{
+		"ETag":           `"etag"`,
+		"Content-Type":   "text/plain",
+		"Cache-Control":  "max-age=60",
+		"Accept-Ranges":  "bytes",
+		"Last-Modified":  modTime.Format(http.TimeFormat),
+		"Content-Length": "21",
+	} {
+		if got := rec.Header().Get(k); got != v {
+			t.Errorf("ObjectHandler header %v: %v, want %v", k, got, v)
+		}
+	}
+	if rec.Header().Get("x-cos-meta-secret") != "" {
+		t.Errorf("ObjectHandler leaked x-cos-meta header")
+	}
+
+	rec = serve(http.MethodGet, "/hello.txt", map[string]string{"Range": "bytes=7-12"})
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "object" || rec.Header().Get("Content-Range") != "bytes 7-12/21" {
+		t.Errorf("ObjectHandler returned %v, %q, %v", rec.Code, rec.Body.String(), rec.Header())
+	}
+
+	rec = serve(http.MethodGet, "/hello.txt", map[string]string{"If-None-Match": `"etag"`})
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 || rec.Header().Get("ETag") != `"etag"` {
+		t.Errorf("ObjectHandler returned %v, %q, %v", rec.Code, rec.Body.String(), rec.Header())
+	}
+	rec = serve(http.MethodGet, "/hello.txt", map[string]string{"If-Modified-Since": modTime.Add(time.Hour).Format(http.TimeFormat)})
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("ObjectHandler returned %v", rec.Code)
+	}
+	rec = serve(http.MethodGet, "/hello.txt", map[string]string{"If-Match": `"other"`})
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("ObjectHandler returned %v", rec.Code)
+	}
+	rec = serve(http.MethodGet, "/hello.txt", map[string]string{"Range": "bytes=100-"})
+	if rec.Code != http.StatusRequestedRangeNotSatisfiable || rec.Header().Get("Content-Range") != "bytes */21" {
+		t.Errorf("ObjectHandler returned %v, %v", rec.Code, rec.Header())
+	}
+
+	rec = serve(http.MethodHead, "/hello.txt", nil)
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 || rec.Header().Get("Content-Length") != "21" || rec.Header().Get("ETag") != `"etag"` {
+		t.Errorf("ObjectHandler returned %v, %q, %v", rec.Code, rec.Body.String(), rec.Header())
+	}
+	rec = serve(http.MethodHead, "/hello.txt", map[string]string{"If-None-Match": `"etag"`})
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("ObjectHandler returned %v", rec.Code)
+	}
+
+	rec = serve(http.MethodGet, "/nocache.txt", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("ObjectHandler returned %v, %v", rec.Code, rec.Header())
+	}
+
+	atomic.StoreInt32(&requests, 0)
+	cases := []struct {
+		method string
+		path   string
+		header map[string]string
+		code   int
+	}{
+		{http.MethodGet, "/notexist.txt", nil, http.StatusNotFound},
+		{http.MethodHead, "/notexist.txt", nil, http.StatusNotFound},
+		{http.MethodGet, "/private.txt", nil, http.StatusForbidden},
+		{http.MethodGet, "/broken.txt", nil, http.StatusBadRequest},
+		{http.MethodGet, "/hello.txt", map[string]string{"X-Token": "deny"}, http.StatusForbidden},
+		{http.MethodGet, "/../secret.txt", nil, http.StatusNotFound},
+		{http.MethodPost, "/hello.txt", nil, http.StatusMethodNotAllowed},
+	}
+	for _, c := range cases {
+		rec = serve(c.method, c.path, c.header)
+		if rec.Code != c.code {
+			t.Errorf("ObjectHandler %v %v returned %v, want %v", c.method, c.path, rec.Code, c.code)
+		}
+	}
+	if requests != 4 {
+		t.Errorf("ObjectHandler sent %d requests, want 4", requests)
+	}
+
+	var gotStatus int
+	h.ErrorHandler = func(w http.ResponseWriter, r *http.Request, status int, err error) {
+		gotStatus = status
+		w.WriteHeader(http.StatusTeapot)
+	}
+	rec = serve(http.MethodGet, "/notexist.txt", nil)
+	if rec.Code != http.StatusTeapot || gotStatus != http.StatusNotFound {
+		t.Errorf("ObjectHandler.ErrorHandler returned %v, status: %v", rec.Code, gotStatus)
+	}
+}
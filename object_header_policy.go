@@ -0,0 +1,110 @@
+package cos
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// UploadHeaderRule 是按对象名匹配的上传头部规则，只填充调用方未设置的头部
+type UploadHeaderRule struct {
+	// path.Match 风格的匹配模式，例如 "static/*.js"；不含 "/" 时匹配对象名的最后一段，例如 "*.html"
+	Pattern            string
+	ContentType        string
+	CacheControl       string
+	ContentDisposition string
+	StorageClass       string
+}
+
+// UploadHeaderPolicy 是客户端级别的上传头部策略，作用于 Put、PutFromFile、Upload、PutFromURL 和 CI.Put
+type UploadHeaderPolicy struct {
+	// 按顺序匹配，第一条匹配的规则生效
+	Rules []UploadHeaderRule
+	// 关闭 PutFromFile/Upload/PutFromURL 的 Content-Type 推断
+	DisableContentTypeDetection bool
+}
+
+// Match 返回第一条匹配 name 的规则，没有匹配时返回 nil
+func (p *UploadHeaderPolicy) Match(name string) *UploadHeaderRule {
+	if p == nil {
+		return nil
+	}
+	for i := range p.Rules {
+		pattern := p.Rules[i].Pattern
+		target := name
+		if !strings.Contains(pattern, "/") {
+			target = path.Base(name)
+		}
+		if ok, _ := path.Match(pattern, target); ok {
+			return &p.Rules[i]
+		}
+	}
+	return nil
+}
+
+// applyUploadHeaderPolicy 按客户端的上传头部策略填充 opt 中未设置的头部
+func (c *Client) applyUploadHeaderPolicy(name string, opt *ObjectPutHeaderOptions) {
+	if opt == nil || c.Conf == nil {
+		return
+	}
+	rule := c.Conf.UploadHeaderPolicy.Match(name)
+	if rule == nil {
+		return
+	}
+	if opt.ContentType == "" {
+		opt.ContentType = rule.ContentType
+	}
+	if opt.CacheControl == "" {
+		opt.CacheControl = rule.CacheControl
+	}
+	if opt.ContentDisposition == "" {
+		opt.ContentDisposition = rule.ContentDisposition
+	}
+	if opt.XCosStorageClass == "" {
+		opt.XCosStorageClass = rule.StorageClass
+	}
+}
+
+func (c *Client) contentTypeDetection() bool {
+	return c.Conf == nil || c.Conf.UploadHeaderPolicy == nil || !c.Conf.UploadHeaderPolicy.DisableContentTypeDetection
+}
+
+// applyUploadHeaders 应用上传头部策略，Content-Type 仍为空时按 filePath、name 的扩展名推断，
+// 最后读取文件头部做内容嗅探，filePath 为空时不做嗅探。
+func (c *Client) applyUploadHeaders(name, filePath string, opt *ObjectPutHeaderOptions) {
+	c.applyUploadHeaderPolicy(name, opt)
+	if opt == nil || opt.ContentType != "" || !c.contentTypeDetection() {
+		return
+	}
+	opt.ContentType = DetectContentType(name, filePath)
+}
+
+// DetectContentType 按本地文件、对象名的扩展名推断 Content-Type，
+// 推断失败时读取文件前 512 字节使用 http.DetectContentType 嗅探，仍然失败时返回空字符串。
+func DetectContentType(name, filePath string) string {
+	for _, p := range []string{filePath, name} {
+		if ext := filepath.Ext(p); ext != "" {
+			if ct := mime.TypeByExtension(ext); ct != "" {
+				return ct
+			}
+		}
+	}
+	if filePath == "" {
+		return ""
+	}
+	fd, err := os.Open(filePath)
+	if err != nil {
+		return ""
+	}
+	defer fd.Close()
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(fd, buf)
+	if n == 0 && err != nil {
+		return ""
+	}
+	return http.DetectContentType(buf[:n])
+}
@@ -0,0 +1,176 @@
+package cos
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUploadHeaderPolicy_Match(t *testing.T) {
+	p := &UploadHeaderPolicy{
+		Rules: []UploadHeaderRule{
+			{Pattern: "static/*.js", CacheControl: "max-age=31536000"},
+			{Pattern: "*.html", CacheControl: "no-cache"},
+			{Pattern: "archive/*", StorageClass: "ARCHIVE"},
+		},
+	}
+	cases := map[string]string{
+		"static/app.js":     "static/*.js",
+		"static/lib/app.js": "",
+		"index.html":        "*.html",
+		"docs/a/index.html": "*.html",
+		"archive/2020.tar":  "archive/*",
+		"app.js":            "",
+	}
+	for name, want := range cases {
+		rule := p.Match(name)
+		if (rule == nil && want != "") || (rule != nil && rule.Pattern != want) {
+			t.Errorf("Match(%q) returned %+v, want %q", name, rule, want)
+		}
+	}
+	var nilPolicy *UploadHeaderPolicy
+	if nilPolicy.Match("index.html") != nil {
+		t.Errorf("nil policy Match expect nil")
+	}
+}
+
+func TestDetectContentType(t *testing.T) {
+	filePath := "tmpfile" + time.Now().Format(time.RFC3339)
+	defer os.Remove(filePath)
+	ioutil.WriteFile(filePath, []byte("<html><body>hello</body></html>"), 0644)
+
+	if got := DetectContentType("a/b.json", ""); got != "application/json" {
+		t.Errorf("DetectContentType by name returned %v", got)
+	}
+	if got := DetectContentType("noext", filePath); !strings.HasPrefix(got, "text/html") {
+		t.Errorf("DetectContentType by sniffing returned %v", got)
+	}
+	if got := DetectContentType("noext", ""); got != "" {
+		t.Errorf("DetectContentType without file returned %v", got)
+	}
+}
+
+func TestObjectService_UploadHeaderPolicy(t *testing.T) {
+	setup()
+	defer teardown()
+
+	client.Conf.EnableCRC = false
+	client.Conf.UploadHeaderPolicy = &UploadHeaderPolicy{
+		Rules: []UploadHeaderRule{
+			{Pattern: "*.css", CacheControl: "max-age=600", StorageClass: "STANDARD_IA"},
+			{Pattern: "download/*", ContentDisposition: "attachment"},
+		},
+	}
+	want := map[string]http.Header{}
+	check := func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Method == http.MethodPost && r.Form.Get("uploadId") != "" {
+			w.Write([]byte(`<CompleteMultipartUploadResult><Key>k</Key><ETag>etag</ETag></CompleteMultipartUploadResult>`))
+			return
+		}
+		if r.Method == http.MethodPut && r.Form.Get("partNumber") != "" {
+			w.Header().Set("ETag", "etag")
+			return
+		}
+		if r.Method == http.MethodGet {
+			w.Write([]byte("report"))
+			return
+		}
+		for k := range want[r.URL.Path] {
+			testHeader(t, r, k, want[r.URL.Path].Get(k))
+		}
+		if r.Method == http.MethodPost {
+			w.Write([]byte(`<InitiateMultipartUploadResult><UploadId>id</UploadId></InitiateMultipartUploadResult>`))
+		}
+	}
+	mux.HandleFunc("/", check)
+
+	filePath := "tmpfile" + time.Now().Format(time.RFC3339)
+	defer os.Remove(filePath)
+	ioutil.WriteFile(filePath, []byte("body { color: red; }"), 0644)
+
+	want["/style.css"] = http.Header{
+		"Content-Type":        {"text/css; charset=utf-8"},
+		"Cache-Control":       {"max-age=600"},
+		"X-Cos-Storage-Class": {"STANDARD_IA"},
+	}
+	_, err := client.Object.PutFromFile(context.Background(), "style.css", filePath, nil)
+	if err != nil {
+		t.Fatalf("Object.PutFromFile returned error: %v", err)
+	}
+	// 调用方设置的头部优先
+	want["/style.css"].Set("Cache-Control", "no-store")
+	_, err = client.Object.Put(context.Background(), "style.css", strings.NewReader("a{}"), &ObjectPutOptions{
+		ObjectPutHeaderOptions: &ObjectPutHeaderOptions{
+			ContentType:  "text/css; charset=utf-8",
+			CacheControl: "no-store",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Object.Put returned error: %v", err)
+	}
+	_, _, err = client.CI.Put(context.Background(), "style.css", strings.NewReader("a{}"), &ObjectPutOptions{
+		ObjectPutHeaderOptions: &ObjectPutHeaderOptions{
+			ContentType:  "text/css; charset=utf-8",
+			CacheControl: "no-store",
+		},
+	})
+	if err != nil {
+		t.Fatalf("CI.Put returned error: %v", err)
+	}
+
+	want["/download/report"] = http.Header{
+		"Content-Type":        {"text/plain; charset=utf-8"},
+		"Content-Disposition": {"attachment"},
+	}
+	_, _, err = client.Object.Upload(context.Background(), "download/report", filePath, nil)
+	if err != nil {
+		t.Fatalf("Object.Upload returned error: %v", err)
+	}
+
+	want["/download/pic"] = http.Header{
+		"Content-Type":        {"text/plain; charset=utf-8"},
+		"Content-Disposition": {"attachment"},
+		"Pic-Operations":      {`{"is_pic_info":1}`},
+	}
+	// 分块上传时在 InitiateMultipartUpload 中设置头部
+	picPath := filePath + ".pic"
+	defer os.Remove(picPath)
+	ioutil.WriteFile(picPath, []byte(strings.Repeat("body { color: red; }\n", 100000)), 0644)
+	picOpt := &MultiUploadOptions{
+		PartSize: 1,
+		OptIni: &InitiateMultipartUploadOptions{
+			ObjectPutHeaderOptions: &ObjectPutHeaderOptions{
+				XOptionHeader: &http.Header{"Pic-Operations": {`{"is_pic_info":1}`}},
+			},
+		},
+	}
+	_, _, err = client.Object.UploadWithPicOperations(context.Background(), "download/pic", picPath, picOpt)
+	if err != nil {
+		t.Fatalf("Object.UploadWithPicOperations returned error: %v", err)
+	}
+	if h := picOpt.OptIni.ObjectPutHeaderOptions; h.ContentType != "" || h.ContentDisposition != "" {
+		t.Errorf("Object.UploadWithPicOperations modified the caller's options: %+v", h)
+	}
+
+	want["/download/report.pdf"] = http.Header{
+		"Content-Type":        {"application/pdf"},
+		"Content-Disposition": {"attachment"},
+	}
+	source := server.URL + "/download/report"
+	_, _, err = client.Object.PutFromURL(context.Background(), "download/report.pdf", source, nil)
+	if err != nil {
+		t.Fatalf("Object.PutFromURL returned error: %v", err)
+	}
+
+	client.Conf.UploadHeaderPolicy.DisableContentTypeDetection = true
+	want["/download/report"].Set("Content-Type", "")
+	_, err = client.Object.PutFromFile(context.Background(), "download/report", filePath, nil)
+	if err != nil {
+		t.Fatalf("Object.PutFromFile returned error: %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package cos
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ObjectMeta 是 Head/Get Object 响应头部解析后的对象元数据
+type ObjectMeta struct {
+	// 本次响应的 Content-Length，范围下载时为分段长度
+	ContentLength int64
+	// 对象的总长度，范围下载时取自 Content-Range
+	Size               int64
+	ContentType        string
+	ContentEncoding    string
+	ContentDisposition string
+	ContentLanguage    string
+	CacheControl       string
+	Expires            string
+	ETag               string
+	LastModified       time.Time
+	StorageClass       string
+	StorageTier        string
+	// x-cos-hash-crc64ecma，HasCRC64 为 false 时表示响应中不存在该头部
+	CRC64        uint64
+	HasCRC64     bool
+	VersionId    string
+	TaggingCount int
+	// Normal 或 appendable
+	ObjectType string
+	// 仅 appendable 对象有效
+	NextAppendPosition int64
+	// 归档对象的恢复状态
+	Restore *ObjectRestoreStatus
+	// SSE-COS/SSE-KMS/SSE-C
+	ServerSideEncryption string
+	SSEKMSKeyId          string
+	SSECustomerAglo      string
+	SSECustomerKeyMD5    string
+	// 自定义的 x-cos-meta-* 头部，key 为去掉前缀后的小写名称
+	Meta      map[string]string
+	RequestID string
+	// 原始响应头部
+	Header http.Header
+}
+
+// IsAppendable 对象是否为追加上传类型
+func (m *ObjectMeta) IsAppendable() bool {
+	return strings.EqualFold(m.ObjectType, "appendable")
+}
+
+// DecodeObjectMeta 从 Head/Get Object 的响应中解析对象元数据。
+//
+// 解析是宽松的：缺失或者格式不合法的头部保留为零值，不会返回错误。
+func DecodeObjectMeta(resp *Response) (*ObjectMeta, error) {
+	if resp == nil || resp.Response == nil {
+		return nil, errors.New("response is nil")
+	}
+	h := resp.Header
+	m := &ObjectMeta{
+		ContentLength:        -1,
+		Size:                 -1,
+		ContentType:          h.Get("Content-Type"),
+		ContentEncoding:      h.Get("Content-Encoding"),
+		ContentDisposition:   h.Get("Content-Disposition"),
+		ContentLanguage:      h.Get("Content-Language"),
+		CacheControl:         h.Get("Cache-Control"),
+		Expires:              h.Get("Expires"),
+		ETag:                 h.Get("ETag"),
+		StorageClass:         h.Get("x-cos-storage-class"),
+		StorageTier:          h.Get("x-cos-storage-tier"),
+		VersionId:            h.Get("x-cos-version-id"),
+		ObjectType:           h.Get("x-cos-object-type"),
+		ServerSideEncryption: h.Get("x-cos-server-side-encryption"),
+		SSEKMSKeyId:          h.Get("x-cos-server-side-encryption-cos-kms-key-id"),
+		SSECustomerAglo:      h.Get("x-cos-server-side-encryption-customer-algorithm"),
+		SSECustomerKeyMD5:    h.Get("x-cos-server-side-encryption-customer-key-MD5"),
+		RequestID:            h.Get("x-cos-request-id"),
+		Header:               h,
+	}
+	// 标准对象默认为 STANDARD，COS 不返回该头部
+	if m.StorageClass == "" {
+		m.StorageClass = "STANDARD"
+	}
+	if v, err := strconv.ParseInt(strings.TrimSpace(h.Get("Content-Length")), 10, 64); err == nil {
+		m.ContentLength = v
+	} else if resp.ContentLength >= 0 {
+		m.ContentLength = resp.ContentLength
+	}
+	m.Size = m.ContentLength
+	if total, ok := parseContentRangeTotal(h.Get("Content-Range")); ok {
+		m.Size = total
+	}
+	if v := h.Get("Last-Modified"); v != "" {
+		m.LastModified, _ = ParseObjectTime(v)
+	}
+	if v := strings.TrimSpace(h.Get("x-cos-hash-crc64ecma")); v != "" {
+		if crc, err := strconv.ParseUint(v, 10, 64); err == nil {
+			m.CRC64, m.HasCRC64 = crc, true
+		}
+	}
+	if v, err := strconv.Atoi(strings.TrimSpace(h.Get("x-cos-tagging-count"))); err == nil {
+		m.TaggingCount = v
+	}
+	if v, err := strconv.ParseInt(strings.TrimSpace(h.Get("x-cos-next-append-position")), 10, 64); err == nil {
+		m.NextAppendPosition = v
+	}
+	m.Restore, _ = GetRestoreStatus(resp)
+	for key, values := range h {
+		lk := strings.ToLower(key)
+		if !strings.HasPrefix(lk, "x-cos-meta-") || len(values) == 0 {
+			continue
+		}
+		if m.Meta == nil {
+			m.Meta = make(map[string]string)
+		}
+		m.Meta[lk[len("x-cos-meta-"):]] = values[0]
+	}
+	return m, nil
+}
+
+// parseContentRangeTotal 解析 "bytes 0-99/1000" 中的总长度
+func parseContentRangeTotal(v string) (int64, bool) {
+	idx := strings.LastIndexByte(v, '/')
+	if idx < 0 {
+		return 0, false
+	}
+	total, err := strconv.ParseInt(strings.TrimSpace(v[idx+1:]), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return total, true
+}
+
+var objectTimeLayouts = []string{
+	http.TimeFormat,
+	time.RFC1123,
+	time.RFC1123Z,
+	time.RFC850,
+	time.ANSIC,
+	time.RFC3339Nano,
+	"Mon, 2 Jan 2006 15:04:05 GMT",
+}
+
+// ParseObjectTime 解析 COS 返回的时间，兼容 HTTP 头部使用的 RFC1123/RFC850/ANSIC
+// 以及 XML 结果中使用的 ISO8601 格式，返回 UTC 时间
+func ParseObjectTime(v string) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	var err error
+	for _, layout := range objectTimeLayouts {
+		var t time.Time
+		t, err = time.Parse(layout, v)
+		if err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// Stat 调用 Head Object 获取对象的元数据，并解析为 ObjectMeta
+func (s *ObjectService) Stat(ctx context.Context, name string, opt *ObjectHeadOptions, id ...string) (*ObjectMeta, *Response, error) {
+	resp, err := s.Head(ctx, name, opt, id...)
+	if err != nil {
+		return nil, resp, err
+	}
+	meta, err := DecodeObjectMeta(resp)
+	return meta, resp, err
+}
@@ -0,0 +1,140 @@
+package cos
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseObjectTime(t *testing.T) {
+	want := time.Date(2017, 6, 12, 5, 36, 19, 0, time.UTC)
+	cases := []string{
+		"Mon, 12 Jun 2017 05:36:19 GMT",
+		" Mon, 12 Jun 2017 05:36:19 GMT ",
+		"Mon, 12 Jun 2017 13:36:19 +0800",
+		"Mon, 12 Jun 2017 05:36:19 UTC",
+		"Monday, 12-Jun-17 05:36:19 GMT",
+		"Mon Jun 12 05:36:19 2017",
+		"2017-06-12T05:36:19Z",
+		"2017-06-12T05:36:19.000Z",
+		"2017-06-12T13:36:19+08:00",
+	}
+	for _, c := range cases {
+		got, err := ParseObjectTime(c)
+		if err != nil {
+			t.Errorf("ParseObjectTime(%q) returned error: %v", c, err)
+			continue
+		}
+		if !got.Equal(want) || got.Location() != time.UTC {
+			t.Errorf("ParseObjectTime(%q) returned %v, want %v", c, got, want)
+		}
+	}
+	got, err := ParseObjectTime("Mon, 5 Jun 2017 05:36:19 GMT")
+	if err != nil || got.Day() != 5 {
+		t.Errorf("ParseObjectTime returned %v, error: %v", got, err)
+	}
+	for _, c := range []string{"", "yesterday", "2017-13-45"} {
+		if _, err := ParseObjectTime(c); err == nil {
+			t.Errorf("ParseObjectTime(%q) expect error", c)
+		}
+	}
+}
+
+func TestDecodeObjectMeta(t *testing.T) {
+	h := http.Header{}
+	h.Set("Content-Length", "100")
+	h.Set("Content-Range", "bytes 0-99/1024")
+	h.Set("Content-Type", "text/plain")
+	h.Set("ETag", "\"098f6bcd4621d373cade4e832627b4f6\"")
+	h.Set("Last-Modified", "Mon, 12 Jun 2017 05:36:19 GMT")
+	h.Set("x-cos-storage-class", "ARCHIVE")
+	h.Set("x-cos-hash-crc64ecma", "18446744073709551615")
+	h.Set("x-cos-version-id", "MTg0NDUxNTc1NjIzMTQ1MDAwODg")
+	h.Set("x-cos-restore", `ongoing-request="false", expiry-date="Sat, 05 Dec 2020 16:00:00 GMT"`)
+	h.Set("x-cos-tagging-count", "2")
+	h.Set("x-cos-object-type", "appendable")
+	h.Set("x-cos-next-append-position", "1024")
+	h.Set("x-cos-server-side-encryption", "cos/kms")
+	h.Set("x-cos-server-side-encryption-cos-kms-key-id", "kms-id")
+	h.Set("x-cos-meta-Author", "cos")
+	h.Set("x-cos-request-id", "reqid")
+
+	meta, err := DecodeObjectMeta(&Response{&http.Response{Header: h, ContentLength: 100}})
+	if err != nil {
+		t.Fatalf("DecodeObjectMeta returned error: %v", err)
+	}
+	if meta.ContentLength != 100 || meta.Size != 1024 {
+		t.Errorf("DecodeObjectMeta length: %v, size: %v", meta.ContentLength, meta.Size)
+	}
+	if !meta.LastModified.Equal(time.Date(2017, 6, 12, 5, 36, 19, 0, time.UTC)) {
+		t.Errorf("DecodeObjectMeta LastModified: %v", meta.LastModified)
+	}
+	if !meta.HasCRC64 || meta.CRC64 != 18446744073709551615 {
+		t.Errorf("DecodeObjectMeta CRC64: %v, %v", meta.CRC64, meta.HasCRC64)
+	}
+	if !meta.Restore.Readable() || meta.Restore.ExpiryDate.IsZero() {
+		t.Errorf("DecodeObjectMeta Restore: %+v", meta.Restore)
+	}
+	if !meta.IsAppendable() || meta.NextAppendPosition != 1024 || meta.TaggingCount != 2 {
+		t.Errorf("DecodeObjectMeta returned %+v", meta)
+	}
+	if meta.ServerSideEncryption != "cos/kms" || meta.SSEKMSKeyId != "kms-id" {
+		t.Errorf("DecodeObjectMeta SSE: %v, %v", meta.ServerSideEncryption, meta.SSEKMSKeyId)
+	}
+	if len(meta.Meta) != 1 || meta.Meta["author"] != "cos" {
+		t.Errorf("DecodeObjectMeta Meta: %v", meta.Meta)
+	}
+	if meta.VersionId != "MTg0NDUxNTc1NjIzMTQ1MDAwODg" || meta.RequestID != "reqid" {
+		t.Errorf("DecodeObjectMeta returned %+v", meta)
+	}
+
+	// 不合法的头部保留为零值
+	h = http.Header{}
+	h.Set("Last-Modified", "yesterday")
+	h.Set("x-cos-hash-crc64ecma", "abc")
+	h.Set("x-cos-tagging-count", "two")
+	h.Set("Content-Range", "bytes */*")
+	meta, err = DecodeObjectMeta(&Response{&http.Response{Header: h, ContentLength: -1}})
+	if err != nil {
+		t.Fatalf("DecodeObjectMeta returned error: %v", err)
+	}
+	if !meta.LastModified.IsZero() || meta.HasCRC64 || meta.TaggingCount != 0 || meta.Size != -1 {
+		t.Errorf("DecodeObjectMeta returned %+v", meta)
+	}
+	if meta.StorageClass != "STANDARD" || meta.Restore.Requested {
+		t.Errorf("DecodeObjectMeta returned %+v", meta)
+	}
+
+	if _, err = DecodeObjectMeta(nil); err == nil {
+		t.Errorf("DecodeObjectMeta expect error")
+	}
+}
+
+func TestObjectService_Stat(t *testing.T) {
+	setup()
+	defer teardown()
+	name := "test/hello.txt"
+
+	mux.HandleFunc("/test/hello.txt", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodHead)
+		testFormValues(t, r, values{"versionId": "v1"})
+		w.Header().Set("Content-Length", "11")
+		w.Header().Set("Last-Modified", "Mon, 12 Jun 2017 05:36:19 GMT")
+		w.Header().Set("x-cos-hash-crc64ecma", "123")
+		w.Header().Set("x-cos-version-id", "v1")
+	})
+
+	meta, _, err := client.Object.Stat(context.Background(), name, nil, "v1")
+	if err != nil {
+		t.Fatalf("Object.Stat returned error: %v", err)
+	}
+	if meta.ContentLength != 11 || meta.Size != 11 || meta.CRC64 != 123 || meta.VersionId != "v1" || meta.LastModified.IsZero() {
+		t.Errorf("Object.Stat returned %+v", meta)
+	}
+
+	_, _, err = client.Object.Stat(context.Background(), "test/notexist.txt", nil)
+	if !IsNotFoundError(err) {
+		t.Errorf("Object.Stat expect not found error, got: %v", err)
+	}
+}
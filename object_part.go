@@ -226,6 +226,8 @@ type CompleteMultipartUploadResult struct {
 	Bucket   string
 	Key      string
 	ETag     string
+	// 对象未变化，跳过了上传
+	Skipped bool `xml:"-"`
 }
 
 // ObjectList can used for sort the parts which needs in complete upload part
@@ -375,7 +377,7 @@ func (s *ObjectService) CopyPart(ctx context.Context, name, uploadID string, par
 	if strings.HasPrefix(sourceURL, "http://") || strings.HasPrefix(sourceURL, "https://") {
 		return nil, nil, errors.New("sourceURL format is invalid.")
 	}
-	surl := strings.SplitN(sourceURL, "/", 2)
+	surl := s.splitCopySource(sourceURL)
 	if len(surl) < 2 {
 		return nil, nil, fmt.Errorf("x-cos-copy-source format error: %s", sourceURL)
 	}
@@ -474,7 +476,9 @@ type MultiCopyOptions struct {
 	OptCopy        *ObjectCopyOptions
 	PartSize       int64
 	ThreadPoolSize int
-	useMulti       bool // use for ut
+	// 聚合所有分块的复制进度，分块复制在服务端完成，进度按分块完成更新
+	Progress *TransferProgressOptions
+	useMulti bool // use for ut
 }
 
 type CopyJobs struct {
@@ -523,8 +527,24 @@ func copyworker(ctx context.Context, s *ObjectService, jobs <-chan *CopyJobs, re
 	}
 }
 
-func (s *ObjectService) innerHead(ctx context.Context, sourceURL string, id []string) (*Response, error) {
+// splitCopySource 将拷贝源拆分为域名和对象键。源与 BucketURL 为同一个 path-style 地址时，
+// /{bucket} 前缀归入域名部分，只对对象键编码，避免 bucket 与对象键之间的 / 被编码为 %2F
+func (s *ObjectService) splitCopySource(sourceURL string) []string {
 	surl := strings.SplitN(sourceURL, "/", 2)
+	if len(surl) < 2 {
+		return surl
+	}
+	if u := s.client.BaseURL.BucketURL; u != nil && u.Host == surl[0] {
+		if prefix := basePath(u); prefix != "" && strings.HasPrefix("/"+surl[1], prefix+"/") {
+			surl[0] += prefix
+			surl[1] = surl[1][len(prefix):]
+		}
+	}
+	return surl
+}
+
+func (s *ObjectService) innerHead(ctx context.Context, sourceURL string, id []string) (*Response, error) {
+	surl := s.splitCopySource(sourceURL)
 	if len(surl) < 2 {
 		return nil, fmt.Errorf("sourceURL format error: %s", sourceURL)
 	}
@@ -583,11 +603,22 @@ func (s *ObjectService) MultiCopy(ctx context.Context, name string, sourceURL st
 	}
 
 	if partNum == 0 || (totalBytes <= singleUploadMaxLength && !opt.useMulti) {
+		tracker := NewTransferTracker(totalBytes, 1, opt.Progress)
+		tracker.Start()
+		var res *ObjectCopyResult
+		var rsp *Response
 		if len(id) > 0 {
-			return s.Copy(ctx, name, sourceURL, opt.OptCopy, id[0])
+			res, rsp, err = s.Copy(ctx, name, sourceURL, opt.OptCopy, id[0])
 		} else {
-			return s.Copy(ctx, name, sourceURL, opt.OptCopy)
+			res, rsp, err = s.Copy(ctx, name, sourceURL, opt.OptCopy)
+		}
+		if err != nil {
+			tracker.Fail(err)
+			return res, rsp, err
 		}
+		tracker.CompletePart(1, totalBytes)
+		tracker.Complete()
+		return res, rsp, err
 	}
 	optini := CopyOptionsToMulti(opt.OptCopy)
 	var uploadID string
@@ -611,6 +642,8 @@ func (s *ObjectService) MultiCopy(ctx context.Context, name string, sourceURL st
 	for w := 1; w <= poolSize; w++ {
 		go copyworker(ctx, s, chjobs, chresults)
 	}
+	tracker := NewTransferTracker(totalBytes, partNum, opt.Progress)
+	tracker.Start()
 
 	go func() {
 		for _, chunk := range chunks {
@@ -649,9 +682,11 @@ func (s *ObjectService) MultiCopy(ctx context.Context, name string, sourceURL st
 		optcom.Parts = append(optcom.Parts, Object{
 			PartNumber: res.PartNumber, ETag: etag},
 		)
+		tracker.CompletePart(res.PartNumber, chunks[res.PartNumber-1].Size)
 	}
 	close(chresults)
 	if err != nil {
+		tracker.Fail(err)
 		return nil, nil, err
 	}
 	sort.Sort(ObjectList(optcom.Parts))
@@ -659,8 +694,10 @@ func (s *ObjectService) MultiCopy(ctx context.Context, name string, sourceURL st
 	v, resp, err := s.CompleteMultipartUpload(ctx, name, uploadID, optcom)
 	if err != nil {
 		s.AbortMultipartUpload(ctx, name, uploadID)
+		tracker.Fail(err)
 		return nil, resp, err
 	}
+	tracker.Complete()
 	cpres := &ObjectCopyResult{
 		ETag:      v.ETag,
 		CRC64:     resp.Header.Get("x-cos-hash-crc64ecma"),
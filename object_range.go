@@ -0,0 +1,273 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ObjectRangePart 是范围下载中单个范围的数据
+type ObjectRangePart struct {
+	// 请求的范围
+	Range RangeOptions
+	// 实际返回的字节区间 [Start, End]
+	Start int64
+	End   int64
+	// 对象总长度，未知时为 -1
+	Size        int64
+	ContentType string
+	Body        io.ReadCloser
+}
+
+// ByteRangesReader 流式解析多范围下载返回的 multipart/byteranges 响应
+type ByteRangesReader struct {
+	resp *Response
+	mr   *multipart.Reader
+}
+
+// NewByteRangesReader 使用 Range 为多个范围的 Get 响应创建 ByteRangesReader，
+// 响应不是 multipart/byteranges 时返回错误，此时服务端只返回了单个范围或者整个对象。
+func NewByteRangesReader(resp *Response) (*ByteRangesReader, error) {
+	if resp == nil || resp.Response == nil {
+		return nil, errors.New("response is nil")
+	}
+	boundary, ok := byteRangesBoundary(resp)
+	if !ok {
+		return nil, fmt.Errorf("response is not multipart/byteranges, StatusCode: %v, Content-Type: %v", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	return &ByteRangesReader{
+		resp: resp,
+		mr:   multipart.NewReader(resp.Body, boundary),
+	}, nil
+}
+
+func byteRangesBoundary(resp *Response) (string, bool) {
+	if resp.StatusCode != http.StatusPartialContent {
+		return "", false
+	}
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || !strings.EqualFold(mediaType, "multipart/byteranges") || params["boundary"] == "" {
+		return "", false
+	}
+	return params["boundary"], true
+}
+
+// NextPart 返回下一个范围，所有范围读取完毕时返回 io.EOF。
+// 返回的 Body 只在下一次调用 NextPart 之前有效。
+func (r *ByteRangesReader) NextPart() (*ObjectRangePart, error) {
+	p, err := r.mr.NextPart()
+	if err != nil {
+		return nil, err
+	}
+	start, end, total, err := parseContentRange(p.Header.Get("Content-Range"))
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectRangePart{
+		Start:       start,
+		End:         end,
+		Size:        total,
+		ContentType: p.Header.Get("Content-Type"),
+		Body:        p,
+	}, nil
+}
+
+// Close 关闭响应
+func (r *ByteRangesReader) Close() error {
+	return r.resp.Body.Close()
+}
+
+// MultiRangeGetOptions 是 GetRanges 的选项
+type MultiRangeGetOptions struct {
+	// Get 的选项，不能指定 Range
+	Opt *ObjectGetOptions
+	// 回退为单范围请求时的并发数，默认为范围的个数
+	ThreadPoolSize int
+	// 不发送多范围请求，直接并发发送单范围请求
+	DisableMultiRange bool
+}
+
+// GetRanges 在一次 Get 请求中下载对象的多个范围，返回的结果与 ranges 一一对应。
+//
+// 服务端返回 multipart/byteranges 时，各范围的数据会被读取到内存中；
+// 服务端只返回单个范围或者整个对象时，回退为并发的单范围请求，此时各范围的 Body 为独立的响应流。
+// 调用方需要关闭所有返回的 Body。
+func (s *ObjectService) GetRanges(ctx context.Context, name string, ranges []RangeOptions, opt *MultiRangeGetOptions, id ...string) ([]*ObjectRangePart, *Response, error) {
+	if len(ranges) == 0 {
+		return nil, nil, errors.New("ranges is empty")
+	}
+	for i := range ranges {
+		if !ranges[i].HasStart && !ranges[i].HasEnd {
+			return nil, nil, fmt.Errorf("Invalid Parameter Range: %+v", ranges[i])
+		}
+	}
+	if opt == nil {
+		opt = &MultiRangeGetOptions{}
+	}
+	if opt.Opt != nil && opt.Opt.Range != "" {
+		return nil, nil, fmt.Errorf("GetRanges doesn't support Range Options")
+	}
+	parts := make([]*ObjectRangePart, len(ranges))
+	var resp *Response
+	if len(ranges) > 1 && !opt.DisableMultiRange {
+		var err error
+		gopt := CloneObjectGetOptions(opt.Opt)
+		gopt.Range = FormatMultiRangeOptions(ranges)
+		resp, err = s.Get(ctx, name, gopt, id...)
+		if err != nil {
+			return nil, resp, err
+		}
+		if _, ok := byteRangesBoundary(resp); ok {
+			err = readByteRanges(resp, ranges, parts)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, resp, err
+		}
+	}
+	var missing []int
+	for i := range parts {
+		if parts[i] == nil {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == 0 {
+		return parts, resp, nil
+	}
+	// 服务端不支持多范围，或者合并后的范围不能覆盖请求的范围，回退为单范围请求
+	rsp, err := s.getRangeParts(ctx, name, ranges, parts, missing, opt, id...)
+	if resp == nil {
+		resp = rsp
+	}
+	return parts, resp, err
+}
+
+// readByteRanges 读取 multipart/byteranges 响应，服务端可能会合并相邻或者重叠的范围
+func readByteRanges(resp *Response, ranges []RangeOptions, parts []*ObjectRangePart) error {
+	br, err := NewByteRangesReader(resp)
+	if err != nil {
+		return err
+	}
+	for {
+		p, err := br.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadAll(p.Body)
+		if err != nil {
+			return err
+		}
+		if int64(len(data)) != p.End-p.Start+1 {
+			return fmt.Errorf("byteranges part length mismatch, Content-Range: %v-%v, got: %v", p.Start, p.End, len(data))
+		}
+		for i := range ranges {
+			if parts[i] != nil {
+				continue
+			}
+			start, end, ok := ranges[i].resolve(p.Size)
+			if !ok || start < p.Start || end > p.End {
+				continue
+			}
+			parts[i] = &ObjectRangePart{
+				Range:       ranges[i],
+				Start:       start,
+				End:         end,
+				Size:        p.Size,
+				ContentType: p.ContentType,
+				Body:        ioutil.NopCloser(bytes.NewReader(data[start-p.Start : end-p.Start+1])),
+			}
+		}
+	}
+}
+
+func (s *ObjectService) getRangeParts(ctx context.Context, name string, ranges []RangeOptions, parts []*ObjectRangePart, idx []int, opt *MultiRangeGetOptions, id ...string) (*Response, error) {
+	poolSize := opt.ThreadPoolSize
+	if poolSize <= 0 || poolSize > len(idx) {
+		poolSize = len(idx)
+	}
+	jobs := make(chan int, len(idx))
+	for _, i := range idx {
+		jobs <- i
+	}
+	close(jobs)
+
+	var mu sync.Mutex
+	var firstResp *Response
+	var firstErr error
+	var wg sync.WaitGroup
+	for w := 0; w < poolSize; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				part, resp, err := s.getRangePart(ctx, name, ranges[i], opt.Opt, id...)
+				mu.Lock()
+				if firstResp == nil {
+					firstResp = resp
+				}
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				parts[i] = part
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		for i, p := range parts {
+			if p != nil {
+				p.Body.Close()
+				parts[i] = nil
+			}
+		}
+	}
+	return firstResp, firstErr
+}
+
+func (s *ObjectService) getRangePart(ctx context.Context, name string, r RangeOptions, opt *ObjectGetOptions, id ...string) (*ObjectRangePart, *Response, error) {
+	gopt := CloneObjectGetOptions(opt)
+	gopt.Range = FormatRangeOptions(&r)
+	resp, err := s.Get(ctx, name, gopt, id...)
+	if err != nil {
+		return nil, resp, err
+	}
+	part := &ObjectRangePart{
+		Range:       r,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        resp.Body,
+	}
+	if resp.StatusCode == http.StatusPartialContent {
+		part.Start, part.End, part.Size, err = parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil {
+			resp.Body.Close()
+			return nil, resp, err
+		}
+		return part, resp, nil
+	}
+	// 服务端忽略了 Range，返回了整个对象
+	var ok bool
+	part.Size = resp.ContentLength
+	part.Start, part.End, ok = r.resolve(part.Size)
+	if !ok {
+		resp.Body.Close()
+		return nil, resp, fmt.Errorf("Invalid Range %v for object size %v", gopt.Range, part.Size)
+	}
+	if _, err = io.CopyN(ioutil.Discard, resp.Body, part.Start); err != nil {
+		resp.Body.Close()
+		return nil, resp, err
+	}
+	part.Body = LimitReadCloser(resp.Body, part.End-part.Start+1).(io.ReadCloser)
+	return part, resp, nil
+}
@@ -0,0 +1,206 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testRangeContent() []byte {
+	b := make([]byte, 1000)
+	for i := range b {
+		b[i] = byte('a' + i%26)
+	}
+	return b
+}
+
+func testReadRangeParts(t *testing.T, parts []*ObjectRangePart, content []byte, want [][2]int64) {
+	if len(parts) != len(want) {
+		t.Fatalf("got %d parts, want %d", len(parts), len(want))
+	}
+	for i, p := range parts {
+		data, err := ioutil.ReadAll(p.Body)
+		p.Body.Close()
+		if err != nil {
+			t.Fatalf("part %d read error: %v", i, err)
+		}
+		if p.Start != want[i][0] || p.End != want[i][1] || p.Size != int64(len(content)) {
+			t.Errorf("part %d range: %d-%d/%d, want %v", i, p.Start, p.End, p.Size, want[i])
+		}
+		if !bytes.Equal(data, content[want[i][0]:want[i][1]+1]) {
+			t.Errorf("part %d data mismatch", i)
+		}
+	}
+}
+
+func TestObjectService_GetRanges(t *testing.T) {
+	setup()
+	defer teardown()
+	content := testRangeContent()
+
+	var reqs int32
+	mux.HandleFunc("/test.parquet", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		atomic.AddInt32(&reqs, 1)
+		testHeader(t, r, "Range", "bytes=0-9,100-199,-8")
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	})
+
+	ranges := []RangeOptions{
+		{HasStart: true, HasEnd: true, Start: 0, End: 9},
+		{HasStart: true, HasEnd: true, Start: 100, End: 199},
+		{HasEnd: true, End: 8},
+	}
+	parts, resp, err := client.Object.GetRanges(context.Background(), "test.parquet", ranges, nil)
+	if err != nil {
+		t.Fatalf("Object.GetRanges returned error: %v", err)
+	}
+	if resp.StatusCode != http.StatusPartialContent || reqs != 1 {
+		t.Errorf("Object.GetRanges StatusCode: %v, requests: %v", resp.StatusCode, reqs)
+	}
+	testReadRangeParts(t, parts, content, [][2]int64{{0, 9}, {100, 199}, {992, 999}})
+}
+
+func TestObjectService_GetRanges_Fallback(t *testing.T) {
+	setup()
+	defer teardown()
+	content := testRangeContent()
+
+	var reqs int32
+	mux.HandleFunc("/single.parquet", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&reqs, 1)
+		// 只支持单个范围
+		if rs := r.Header.Get("Range"); strings.Contains(rs, ",") {
+			r.Header.Set("Range", strings.SplitN(rs, ",", 2)[0])
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	})
+	mux.HandleFunc("/full.parquet", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&reqs, 1)
+		// 忽略 Range，返回整个对象
+		w.Write(content)
+	})
+
+	ranges := []RangeOptions{
+		{HasStart: true, HasEnd: true, Start: 10, End: 19},
+		{HasStart: true, Start: 990},
+		{HasStart: true, HasEnd: true, Start: 500, End: 5000},
+	}
+	want := [][2]int64{{10, 19}, {990, 999}, {500, 999}}
+	for _, name := range []string{"single.parquet", "full.parquet"} {
+		reqs = 0
+		parts, _, err := client.Object.GetRanges(context.Background(), name, ranges, &MultiRangeGetOptions{ThreadPoolSize: 2})
+		if err != nil {
+			t.Fatalf("Object.GetRanges returned error: %v", err)
+		}
+		if reqs != 4 {
+			t.Errorf("Object.GetRanges %v sent %d requests, want 4", name, reqs)
+		}
+		testReadRangeParts(t, parts, content, want)
+	}
+
+	reqs = 0
+	parts, _, err := client.Object.GetRanges(context.Background(), "single.parquet", ranges, &MultiRangeGetOptions{DisableMultiRange: true})
+	if err != nil {
+		t.Fatalf("Object.GetRanges returned error: %v", err)
+	}
+	if reqs != 3 {
+		t.Errorf("Object.GetRanges sent %d requests, want 3", reqs)
+	}
+	testReadRangeParts(t, parts, content, want)
+}
+
+func TestObjectService_GetRanges_Merged(t *testing.T) {
+	setup()
+	defer teardown()
+	content := testRangeContent()
+
+	mux.HandleFunc("/merged.parquet", func(w http.ResponseWriter, r *http.Request) {
+		// 服务端将重叠的范围合并后返回
+		r.Header.Set("Range", "bytes=0-49,900-999")
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	})
+	ranges := []RangeOptions{
+		{HasStart: true, HasEnd: true, Start: 0, End: 29},
+		{HasStart: true, HasEnd: true, Start: 20, End: 49},
+		{HasEnd: true, End: 100},
+	}
+	parts, _, err := client.Object.GetRanges(context.Background(), "merged.parquet", ranges, nil)
+	if err != nil {
+		t.Fatalf("Object.GetRanges returned error: %v", err)
+	}
+	testReadRangeParts(t, parts, content, [][2]int64{{0, 29}, {20, 49}, {900, 999}})
+}
+
+func TestObjectService_GetRanges_Error(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/notexist", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	ranges := []RangeOptions{{HasStart: true, HasEnd: true, Start: 0, End: 1}, {HasStart: true, Start: 5}}
+	_, _, err := client.Object.GetRanges(context.Background(), "notexist", ranges, nil)
+	if !IsNotFoundError(err) {
+		t.Errorf("Object.GetRanges expect not found error, got: %v", err)
+	}
+	_, _, err = client.Object.GetRanges(context.Background(), "notexist", ranges, &MultiRangeGetOptions{DisableMultiRange: true})
+	if !IsNotFoundError(err) {
+		t.Errorf("Object.GetRanges expect not found error, got: %v", err)
+	}
+	if _, _, err = client.Object.GetRanges(context.Background(), "notexist", nil, nil); err == nil {
+		t.Errorf("Object.GetRanges expect error")
+	}
+	if _, _, err = client.Object.GetRanges(context.Background(), "notexist", []RangeOptions{{}}, nil); err == nil {
+		t.Errorf("Object.GetRanges expect error")
+	}
+	opt := &MultiRangeGetOptions{Opt: &ObjectGetOptions{Range: "bytes=0-1"}}
+	if _, _, err = client.Object.GetRanges(context.Background(), "notexist", ranges, opt); err == nil {
+		t.Errorf("Object.GetRanges expect error")
+	}
+}
+
+func TestNewByteRangesReader(t *testing.T) {
+	setup()
+	defer teardown()
+	content := testRangeContent()
+
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	})
+	resp, err := client.Object.Get(context.Background(), "test", &ObjectGetOptions{Range: "bytes=1-2,5-6"})
+	if err != nil {
+		t.Fatalf("Object.Get returned error: %v", err)
+	}
+	br, err := NewByteRangesReader(resp)
+	if err != nil {
+		t.Fatalf("NewByteRangesReader returned error: %v", err)
+	}
+	defer br.Close()
+	var got []string
+	for {
+		p, err := br.NextPart()
+		if err != nil {
+			break
+		}
+		data, _ := ioutil.ReadAll(p.Body)
+		got = append(got, string(data))
+	}
+	if strings.Join(got, ",") != "bc,fg" {
+		t.Errorf("ByteRangesReader returned %v", got)
+	}
+
+	resp, err = client.Object.Get(context.Background(), "test", &ObjectGetOptions{Range: "bytes=1-2"})
+	if err != nil {
+		t.Fatalf("Object.Get returned error: %v", err)
+	}
+	defer resp.Body.Close()
+	if _, err = NewByteRangesReader(resp); err == nil {
+		t.Errorf("NewByteRangesReader expect error")
+	}
+}
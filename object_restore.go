@@ -0,0 +1,347 @@
+package cos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 归档恢复的取回模式
+const (
+	RestoreTierExpedited = "Expedited"
+	RestoreTierStandard  = "Standard"
+	RestoreTierBulk      = "Bulk"
+)
+
+const (
+	defaultRestoreDays            = 1
+	defaultRestorePollInterval    = 30 * time.Second
+	defaultRestoreMaxPollInterval = 10 * time.Minute
+)
+
+// ObjectRestoreStatus 是 x-cos-restore 头部解析后的恢复状态
+//
+//	x-cos-restore: ongoing-request="true"
+//	x-cos-restore: ongoing-request="false", expiry-date="Sat, 05 Dec 2020 16:00:00 GMT"
+type ObjectRestoreStatus struct {
+	// 对象的存储类型，来自 x-cos-storage-class
+	StorageClass string
+	// 智能分层对象的存储层级，来自 x-cos-storage-tier
+	StorageTier string
+	// 是否已经发起过恢复（即响应中存在 x-cos-restore）
+	Requested bool
+	// 恢复任务是否仍在进行中
+	Ongoing bool
+	// 恢复出的临时副本的过期时间，恢复完成前为零值
+	ExpiryDate time.Time
+	// x-cos-restore 原始值
+	Raw string
+}
+
+// NeedRestore 对象是否处于归档状态，需要恢复后才能读取
+func (st *ObjectRestoreStatus) NeedRestore() bool {
+	if st == nil {
+		return false
+	}
+	switch strings.ToUpper(st.StorageClass) {
+	case "ARCHIVE", "DEEP_ARCHIVE":
+		return true
+	}
+	switch strings.ToUpper(st.StorageTier) {
+	case "ARCHIVE_ACCESS", "DEEP_ARCHIVE_ACCESS":
+		return true
+	}
+	return false
+}
+
+// Readable 对象当前是否可读：非归档对象，或者归档对象已恢复完成
+func (st *ObjectRestoreStatus) Readable() bool {
+	if st == nil {
+		return false
+	}
+	if !st.NeedRestore() {
+		return true
+	}
+	return st.Requested && !st.Ongoing
+}
+
+// ParseRestoreStatus 解析 x-cos-restore 头部，空字符串表示未发起过恢复。
+// 解析是宽松的：未知字段被忽略，无法解析的 expiry-date 保留为零值。
+func ParseRestoreStatus(value string) (*ObjectRestoreStatus, error) {
+	st := &ObjectRestoreStatus{Raw: value}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return st, nil
+	}
+	st.Requested = true
+	var err error
+	for len(value) > 0 {
+		var key, val string
+		eq := strings.IndexByte(value, '=')
+		if eq < 0 {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(value[:eq]))
+		value = strings.TrimSpace(value[eq+1:])
+		if strings.HasPrefix(value, "\"") {
+			end := strings.IndexByte(value[1:], '"')
+			if end < 0 {
+				val, value = value[1:], ""
+			} else {
+				val, value = value[1:end+1], value[end+2:]
+			}
+		} else {
+			// expiry-date 不带引号时，日期中本身含有逗号，以下一个 "key=" 作为边界
+			next := nextRestoreField(value)
+			val, value = value[:next], value[next:]
+		}
+		value = strings.TrimLeft(value, ", ")
+		val = strings.TrimSpace(val)
+		switch key {
+		case "ongoing-request":
+			st.Ongoing = strings.EqualFold(val, "true")
+		case "expiry-date":
+			t, e := ParseObjectTime(val)
+			if e != nil {
+				err = fmt.Errorf("invalid expiry-date in x-cos-restore: %v", val)
+				continue
+			}
+			st.ExpiryDate = t
+		}
+	}
+	return st, err
+}
+
+func nextRestoreField(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] != ',' {
+			continue
+		}
+		rest := strings.TrimLeft(s[i+1:], " ")
+		eq := strings.IndexByte(rest, '=')
+		if eq > 0 && !strings.ContainsAny(rest[:eq], " ,") {
+			return i
+		}
+	}
+	return len(s)
+}
+
+// GetRestoreStatus 从 Head/Get 的响应中解析归档恢复状态
+func GetRestoreStatus(resp *Response) (*ObjectRestoreStatus, error) {
+	if resp == nil {
+		return nil, errors.New("response is nil")
+	}
+	st, err := ParseRestoreStatus(resp.Header.Get("x-cos-restore"))
+	st.StorageClass = resp.Header.Get("x-cos-storage-class")
+	st.StorageTier = resp.Header.Get("x-cos-storage-tier")
+	return st, err
+}
+
+// RestoreWaitOptions 是 RestoreAndWait/RestorePrefix 的选项
+type RestoreWaitOptions struct {
+	// 恢复出的临时副本的保留天数，默认 1 天
+	Days int
+	// 取回模式: Expedited, Standard, Bulk，为空时由 COS 决定
+	Tier string
+	// 首次轮询的间隔，之后按指数退避增长，默认 30s
+	PollInterval time.Duration
+	// 轮询间隔的上限，默认 10min
+	MaxPollInterval time.Duration
+	// 整体超时时间，从调用开始计算；为 0 时只受 ctx 控制
+	Timeout time.Duration
+	// RestorePrefix 的并发数，默认为 1
+	ThreadPoolSize int
+	// 只发起恢复，不等待恢复完成
+	NoWait bool
+	// Head 请求的选项，例如 SSE-C 头部
+	HeadOpt *ObjectHeadOptions
+	//兼容其他自定义头部
+	XOptionHeader *http.Header
+}
+
+// RestoreAndWait 对归档对象发起恢复，并轮询 Head 直到对象可读。
+//
+// 对象不是归档类型，或者已恢复完成时直接返回；对象正在恢复时不会重复发起恢复。
+// 轮询间隔从 PollInterval 开始按指数退避增长，直到 MaxPollInterval。
+func (s *ObjectService) RestoreAndWait(ctx context.Context, name string, opt *RestoreWaitOptions, id ...string) (*ObjectRestoreStatus, *Response, error) {
+	if len(id) > 1 {
+		return nil, nil, errors.New("wrong params")
+	}
+	if opt == nil {
+		opt = &RestoreWaitOptions{}
+	}
+	if opt.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opt.Timeout)
+		defer cancel()
+	}
+	st, resp, err := s.headRestoreStatus(ctx, name, opt, id...)
+	if err != nil {
+		return st, resp, err
+	}
+	if st.Readable() {
+		return st, resp, nil
+	}
+	if !st.Requested {
+		ropt := &ObjectRestoreOptions{
+			Days:          opt.Days,
+			XOptionHeader: opt.XOptionHeader,
+		}
+		if ropt.Days <= 0 {
+			ropt.Days = defaultRestoreDays
+		}
+		if opt.Tier != "" {
+			ropt.Tier = &CASJobParameters{Tier: opt.Tier}
+		}
+		resp, err = s.PostRestore(ctx, name, ropt, id...)
+		// 并发场景下其他调用方可能已经发起恢复
+		if err != nil && !isRestoreAlreadyInProgress(err) {
+			return st, resp, err
+		}
+		st.Requested = true
+		st.Ongoing = true
+	}
+	if opt.NoWait {
+		return st, resp, nil
+	}
+
+	interval := opt.PollInterval
+	if interval <= 0 {
+		interval = defaultRestorePollInterval
+	}
+	maxInterval := opt.MaxPollInterval
+	if maxInterval <= 0 {
+		maxInterval = defaultRestoreMaxPollInterval
+	}
+	for {
+		if err = sleepWithContext(ctx, interval); err != nil {
+			return st, resp, err
+		}
+		st, resp, err = s.headRestoreStatus(ctx, name, opt, id...)
+		if err != nil {
+			return st, resp, err
+		}
+		if st.Readable() {
+			return st, resp, nil
+		}
+		interval *= 2
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+func (s *ObjectService) headRestoreStatus(ctx context.Context, name string, opt *RestoreWaitOptions, id ...string) (*ObjectRestoreStatus, *Response, error) {
+	resp, err := s.Head(ctx, name, opt.HeadOpt, id...)
+	if err != nil {
+		return nil, resp, err
+	}
+	// x-cos-restore 格式不合法时不影响轮询，以 ongoing-request 为准
+	st, _ := GetRestoreStatus(resp)
+	return st, resp, nil
+}
+
+func isRestoreAlreadyInProgress(err error) bool {
+	status, code, ok := serviceError(err)
+	return ok && (code == ErrRestoreAlreadyInProgress || status == http.StatusConflict)
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// ObjectRestoreResult 是 RestorePrefix 中单个对象的恢复结果
+type ObjectRestoreResult struct {
+	Key    string
+	Status *ObjectRestoreStatus
+	Err    error
+}
+
+// RestorePrefix 列出 prefix 下所有归档对象，并发调用 RestoreAndWait 恢复。
+//
+// 非归档对象会被跳过。单个对象的失败记录在对应的 ObjectRestoreResult.Err 中，
+// 返回的 error 仅表示列举失败或者整体超时。
+func (s *ObjectService) RestorePrefix(ctx context.Context, prefix string, opt *RestoreWaitOptions) ([]ObjectRestoreResult, error) {
+	if opt == nil {
+		opt = &RestoreWaitOptions{}
+	}
+	if opt.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opt.Timeout)
+		defer cancel()
+	}
+	// 整体超时已由 ctx 控制
+	wopt := *opt
+	wopt.Timeout = 0
+
+	poolSize := opt.ThreadPoolSize
+	if poolSize <= 0 {
+		poolSize = 1
+	}
+	keys := make(chan string, poolSize)
+	var mu sync.Mutex
+	var results []ObjectRestoreResult
+	var wg sync.WaitGroup
+	for w := 0; w < poolSize; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range keys {
+				st, _, err := s.RestoreAndWait(ctx, key, &wopt)
+				mu.Lock()
+				results = append(results, ObjectRestoreResult{Key: key, Status: st, Err: err})
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var err error
+	gopt := &BucketGetOptions{
+		Prefix:       prefix,
+		EncodingType: "url",
+		MaxKeys:      1000,
+	}
+	isTruncated := true
+	for isTruncated && err == nil {
+		var res *BucketGetResult
+		res, _, err = s.client.Bucket.Get(ctx, gopt)
+		if err != nil {
+			break
+		}
+		for _, obj := range res.Contents {
+			st := &ObjectRestoreStatus{StorageClass: obj.StorageClass, StorageTier: obj.StorageTier}
+			if !st.NeedRestore() {
+				continue
+			}
+			key, e := decodeURIComponent(obj.Key)
+			if e != nil {
+				err = e
+				break
+			}
+			select {
+			case keys <- key:
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+			if err != nil {
+				break
+			}
+		}
+		isTruncated = res.IsTruncated
+		gopt.Marker, _ = decodeURIComponent(res.NextMarker)
+	}
+	close(keys)
+	wg.Wait()
+	return results, err
+}
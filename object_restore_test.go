@@ -0,0 +1,203 @@
+package cos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRestoreStatus(t *testing.T) {
+	expiry := time.Date(2020, 12, 5, 16, 0, 0, 0, time.UTC)
+	cases := []struct {
+		value     string
+		requested bool
+		ongoing   bool
+		expiry    time.Time
+		hasErr    bool
+	}{
+		{"", false, false, time.Time{}, false},
+		{`ongoing-request="true"`, true, true, time.Time{}, false},
+		{`ongoing-request="false", expiry-date="Sat, 05 Dec 2020 16:00:00 GMT"`, true, false, expiry, false},
+		{`ongoing-request="false", expiry-date=Sat, 05 Dec 2020 16:00:00 GMT`, true, false, expiry, false},
+		{`expiry-date="Saturday, 05-Dec-20 16:00:00 GMT", ongoing-request="FALSE"`, true, false, expiry, false},
+		{`ongoing-request="false", expiry-date="not a date"`, true, false, time.Time{}, true},
+		{`ongoing-request="true", unknown="x"`, true, true, time.Time{}, false},
+	}
+	for _, c := range cases {
+		st, err := ParseRestoreStatus(c.value)
+		if (err != nil) != c.hasErr {
+			t.Errorf("ParseRestoreStatus(%q) error: %v, want error: %v", c.value, err, c.hasErr)
+		}
+		if st.Requested != c.requested || st.Ongoing != c.ongoing || !st.ExpiryDate.Equal(c.expiry) {
+			t.Errorf("ParseRestoreStatus(%q) returned %+v", c.value, st)
+		}
+	}
+}
+
+func TestObjectRestoreStatus_Readable(t *testing.T) {
+	cases := []struct {
+		st       *ObjectRestoreStatus
+		readable bool
+	}{
+		{nil, false},
+		{&ObjectRestoreStatus{StorageClass: "STANDARD"}, true},
+		{&ObjectRestoreStatus{StorageClass: "ARCHIVE"}, false},
+		{&ObjectRestoreStatus{StorageClass: "DEEP_ARCHIVE", Requested: true, Ongoing: true}, false},
+		{&ObjectRestoreStatus{StorageClass: "ARCHIVE", Requested: true}, true},
+		{&ObjectRestoreStatus{StorageClass: "INTELLIGENT_TIERING", StorageTier: "ARCHIVE_ACCESS"}, false},
+		{&ObjectRestoreStatus{StorageClass: "INTELLIGENT_TIERING", StorageTier: "FREQUENT"}, true},
+	}
+	for i, c := range cases {
+		if got := c.st.Readable(); got != c.readable {
+			t.Errorf("case %d: Readable returned %v, want %v", i, got, c.readable)
+		}
+	}
+}
+
+func TestObjectService_RestoreAndWait(t *testing.T) {
+	setup()
+	defer teardown()
+	name := "test/archive.txt"
+
+	var heads, posts int32
+	mux.HandleFunc("/test/archive.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-cos-storage-class", "ARCHIVE")
+		switch r.Method {
+		case http.MethodPost:
+			atomic.AddInt32(&posts, 1)
+			testFormValues(t, r, values{"restore": ""})
+			testBody(t, r, "<RestoreRequest><Days>2</Days><CASJobParameters><Tier>Bulk</Tier></CASJobParameters></RestoreRequest>")
+			w.WriteHeader(http.StatusAccepted)
+		case http.MethodHead:
+			n := atomic.AddInt32(&heads, 1)
+			if atomic.LoadInt32(&posts) == 0 {
+				return
+			}
+			if n < 4 {
+				w.Header().Set("x-cos-restore", `ongoing-request="true"`)
+				return
+			}
+			w.Header().Set("x-cos-restore", `ongoing-request="false", expiry-date="Sat, 05 Dec 2020 16:00:00 GMT"`)
+		default:
+			t.Errorf("unexpected method %v", r.Method)
+		}
+	})
+
+	opt := &RestoreWaitOptions{
+		Days:            2,
+		Tier:            RestoreTierBulk,
+		PollInterval:    time.Millisecond,
+		MaxPollInterval: 2 * time.Millisecond,
+	}
+	st, _, err := client.Object.RestoreAndWait(context.Background(), name, opt)
+	if err != nil {
+		t.Fatalf("Object.RestoreAndWait returned error: %v", err)
+	}
+	if !st.Readable() || st.ExpiryDate.IsZero() {
+		t.Errorf("Object.RestoreAndWait returned %+v", st)
+	}
+	if posts != 1 || heads != 4 {
+		t.Errorf("Object.RestoreAndWait sent %d posts and %d heads", posts, heads)
+	}
+}
+
+func TestObjectService_RestoreAndWait_InProgress(t *testing.T) {
+	setup()
+	defer teardown()
+	name := "test/archive.txt"
+
+	mux.HandleFunc("/test/archive.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-cos-storage-class", "DEEP_ARCHIVE")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, "<Error><Code>RestoreAlreadyInProgress</Code></Error>")
+			return
+		}
+		w.Header().Set("x-cos-restore", `ongoing-request="true"`)
+	})
+
+	// 对象正在恢复中，不会再次发起恢复，并在超时后返回
+	opt := &RestoreWaitOptions{
+		PollInterval: time.Millisecond,
+		Timeout:      50 * time.Millisecond,
+	}
+	st, _, err := client.Object.RestoreAndWait(context.Background(), name, opt)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Object.RestoreAndWait expect DeadlineExceeded, got: %v", err)
+	}
+	if st == nil || !st.Ongoing {
+		t.Errorf("Object.RestoreAndWait returned %+v", st)
+	}
+
+	opt.NoWait = true
+	st, _, err = client.Object.RestoreAndWait(context.Background(), name, opt)
+	if err != nil || !st.Ongoing {
+		t.Fatalf("Object.RestoreAndWait returned %+v, error: %v", st, err)
+	}
+}
+
+func TestObjectService_RestoreAndWait_NotArchived(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/test/hello.txt", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodHead)
+		w.Header().Set("x-cos-storage-class", "STANDARD")
+	})
+	st, _, err := client.Object.RestoreAndWait(context.Background(), "test/hello.txt", nil)
+	if err != nil || !st.Readable() {
+		t.Fatalf("Object.RestoreAndWait returned %+v, error: %v", st, err)
+	}
+}
+
+func TestObjectService_RestorePrefix(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			w.Header().Set("x-cos-storage-class", "ARCHIVE")
+			if r.Method == http.MethodHead {
+				w.Header().Set("x-cos-restore", `ongoing-request="false", expiry-date="Sat, 05 Dec 2020 16:00:00 GMT"`)
+			}
+			return
+		}
+		testMethod(t, r, http.MethodGet)
+		if r.URL.Query().Get("marker") == "" {
+			fmt.Fprint(w, `<ListBucketResult>
+	<IsTruncated>true</IsTruncated>
+	<NextMarker>data%2Fb</NextMarker>
+	<Contents><Key>data%2Fa</Key><StorageClass>ARCHIVE</StorageClass></Contents>
+	<Contents><Key>data%2Fb</Key><StorageClass>STANDARD</StorageClass></Contents>
+</ListBucketResult>`)
+			return
+		}
+		testFormValues(t, r, values{"prefix": "data/", "marker": "data/b", "encoding-type": "url", "max-keys": "1000"})
+		fmt.Fprint(w, `<ListBucketResult>
+	<IsTruncated>false</IsTruncated>
+	<Contents><Key>data%2Fc</Key><StorageClass>DEEP_ARCHIVE</StorageClass></Contents>
+</ListBucketResult>`)
+	})
+
+	res, err := client.Object.RestorePrefix(context.Background(), "data/", &RestoreWaitOptions{
+		PollInterval:   time.Millisecond,
+		ThreadPoolSize: 2,
+	})
+	if err != nil {
+		t.Fatalf("Object.RestorePrefix returned error: %v", err)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Key < res[j].Key })
+	if len(res) != 2 || res[0].Key != "data/a" || res[1].Key != "data/c" {
+		t.Fatalf("Object.RestorePrefix returned %+v", res)
+	}
+	for _, r := range res {
+		if r.Err != nil || !r.Status.Readable() {
+			t.Errorf("Object.RestorePrefix result %+v", r)
+		}
+	}
+}
@@ -58,6 +58,14 @@ type ObjectSelectOptions struct {
 	InputSerialization  *SelectInputSerialization  `xml:"InputSerialization"`
 	OutputSerialization *SelectOutputSerialization `xml:"OutputSerialization"`
 	RequestProgress     string                     `xml:"RequestProgress>Enabled,omitempty"`
+	// 只检索起始位置在该范围内的记录，仅支持未压缩的 CSV 与 JSON LINES
+	ScanRange *SelectScanRange `xml:"ScanRange,omitempty"`
+}
+
+// SelectScanRange 是检索的字节范围 [Start, End]，End 为 0 时不发送，表示从 Start 到对象末尾
+type SelectScanRange struct {
+	Start int64 `xml:"Start"`
+	End   int64 `xml:"End,omitempty"`
 }
 
 func (s *ObjectService) Select(ctx context.Context, name string, opt *ObjectSelectOptions) (io.ReadCloser, error) {
@@ -170,6 +178,10 @@ type ObjectSelectResponse struct {
 	Body       io.ReadCloser
 	Frame      *ObjectSelectResult
 	Finish     bool
+	// 读取过程中收到 Progress 帧时回调，需要开启 ObjectSelectOptions.RequestProgress
+	OnProgress func(frame *ProgressFrame)
+	// 读取过程中收到 Stats 帧时回调
+	OnStats func(frame *StatsFrame)
 }
 
 func (osr *ObjectSelectResponse) Read(p []byte) (n int, err error) {
@@ -221,11 +233,19 @@ func (osr *ObjectSelectResponse) readFrames(p []byte) (int, error) {
 			if err != nil {
 				return nlen, err
 			}
+			if osr.OnProgress != nil {
+				frame := osr.Frame.ProgressFrame
+				osr.OnProgress(&frame)
+			}
 		case kStatsFrameType:
 			err := osr.analysisXml(&osr.Frame.StatsFrame)
 			if err != nil {
 				return nlen, err
 			}
+			if osr.OnStats != nil {
+				frame := osr.Frame.StatsFrame
+				osr.OnStats(&frame)
+			}
 		case kEndFrameType:
 			err = osr.payloadChecksum("EndFrame")
 			if err != nil {
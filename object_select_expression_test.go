@@ -0,0 +1,73 @@
+package cos
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuoteSelectValue(t *testing.T) {
+	n := 5
+	var np *int
+	cases := []struct {
+		value interface{}
+		want  string
+	}{
+		{"O'Hare", "'O''Hare'"},
+		{10, "10"},
+		{uint8(3), "3"},
+		{1.5, "1.5"},
+		{true, "TRUE"},
+		{nil, "NULL"},
+		{&n, "5"},
+		{np, "NULL"},
+		{time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), "'2020-01-02T03:04:05Z'"},
+	}
+	for _, c := range cases {
+		got, err := QuoteSelectValue(c.value)
+		if err != nil || got != c.want {
+			t.Errorf("QuoteSelectValue(%v) returned %v, %v, want %v", c.value, got, err, c.want)
+		}
+	}
+	if _, err := QuoteSelectValue([]int{1}); err == nil {
+		t.Errorf("QuoteSelectValue expect error")
+	}
+	if got := QuoteSelectIdentifier(`a"b`); got != `"a""b"` {
+		t.Errorf("QuoteSelectIdentifier returned %v", got)
+	}
+	if got := QuoteSelectIdentifier("_12"); got != "_12" {
+		t.Errorf("QuoteSelectIdentifier returned %v", got)
+	}
+}
+
+func TestSelectExpression(t *testing.T) {
+	expr, err := NewSelectExpression().Build()
+	if err != nil || expr != "SELECT * FROM COSObject s" {
+		t.Errorf("SelectExpression returned %v, %v", expr, err)
+	}
+	expr, err = NewSelectExpression().
+		Columns("name", "_2", "addr.city").
+		Where("age", ">=", 18).
+		Where("name", "like", "O'%").
+		WhereIn("level", "a", 2).
+		WhereNull("deleted", true).
+		WhereNull("email", false).
+		Limit(10).
+		Build()
+	want := `SELECT s."name", s._2, s."addr"."city" FROM COSObject s WHERE s."age" >= 18 AND s."name" LIKE 'O''%' AND s."level" IN ('a', 2) AND s."deleted" IS NULL AND s."email" IS NOT NULL LIMIT 10`
+	if err != nil || expr != want {
+		t.Errorf("SelectExpression returned %v, %v, want %v", expr, err, want)
+	}
+
+	errCases := []*SelectExpression{
+		NewSelectExpression().Where("a", "; DROP", 1),
+		NewSelectExpression().Where("", "=", 1),
+		NewSelectExpression().Where("a", "=", struct{}{}),
+		NewSelectExpression().Columns(""),
+		NewSelectExpression().WhereIn("a"),
+	}
+	for i, e := range errCases {
+		if _, err := e.Build(); err == nil {
+			t.Errorf("case %d: SelectExpression expect error", i)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+//go:build go1.18
+// +build go1.18
+
+package cos
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+)
+
+// SelectRowIterator 流式迭代 Select 返回的记录
+//
+//	it, err := cos.SelectRows[Row](ctx, c.Object, "data.csv", opt, nil)
+//	if err != nil {
+//		return err
+//	}
+//	defer it.Close()
+//	for it.Next() {
+//		row := it.Row()
+//		...
+//	}
+//	return it.Err()
+type SelectRowIterator[T any] struct {
+	resp *ObjectSelectResponse
+	dec  selectRowDecoder
+	row  T
+	err  error
+	done bool
+}
+
+// SelectRows 调用 Select，并将返回的 CSV 或 JSON Lines 记录解码为 T。
+//
+// T 必须为结构体：JSON 记录使用 encoding/json 解码（json tag）；CSV 记录按列名与字段的 csv tag 对应，
+// 列名由 SelectRowsOptions 的 Columns/HeaderRow 指定，默认为 _1, _2, ...
+func SelectRows[T any](ctx context.Context, s *ObjectService, name string, opt *ObjectSelectOptions, ropt *SelectRowsOptions) (*SelectRowIterator[T], error) {
+	if opt == nil {
+		return nil, errors.New("ObjectSelectOptions is nil")
+	}
+	if ropt == nil {
+		ropt = &SelectRowsOptions{}
+	}
+	var zero T
+	typ := reflect.TypeOf(&zero).Elem()
+	// 在发送请求前校验参数
+	if _, err := newSelectRowDecoder(typ, nil, opt.OutputSerialization, ropt); err != nil {
+		return nil, err
+	}
+	rc, err := s.Select(ctx, name, opt)
+	if err != nil {
+		return nil, err
+	}
+	resp := rc.(*ObjectSelectResponse)
+	resp.OnProgress = ropt.OnProgress
+	resp.OnStats = ropt.OnStats
+	dec, err := newSelectRowDecoder(typ, resp, opt.OutputSerialization, ropt)
+	if err != nil {
+		resp.Close()
+		return nil, err
+	}
+	return &SelectRowIterator[T]{resp: resp, dec: dec}, nil
+}
+
+// Next 读取下一条记录，没有更多记录或者出错时返回 false
+func (it *SelectRowIterator[T]) Next() bool {
+	if it.done {
+		return false
+	}
+	var row T
+	err := it.dec.decode(reflect.ValueOf(&row).Elem())
+	if err != nil {
+		it.done = true
+		if err != io.EOF {
+			it.err = err
+		}
+		return false
+	}
+	it.row = row
+	return true
+}
+
+// Row 返回当前记录
+func (it *SelectRowIterator[T]) Row() T {
+	return it.row
+}
+
+// Err 返回迭代过程中的错误，正常结束时为 nil
+func (it *SelectRowIterator[T]) Err() error {
+	return it.err
+}
+
+// Response 返回 Select 的响应，读取完毕后可以从 Frame.StatsFrame 获取统计信息
+func (it *SelectRowIterator[T]) Response() *ObjectSelectResponse {
+	return it.resp
+}
+
+// Close 关闭响应
+func (it *SelectRowIterator[T]) Close() error {
+	it.done = true
+	return it.resp.Close()
+}
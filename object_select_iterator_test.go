@@ -0,0 +1,174 @@
+//go:build go1.18
+// +build go1.18
+
+package cos
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type testSelectRow struct {
+	Name     string    `csv:"name" json:"name"`
+	Age      int       `csv:"age" json:"age"`
+	Score    *float64  `csv:"score" json:"score"`
+	Active   bool      `json:"active"`
+	Birthday time.Time `csv:"birthday" json:"birthday"`
+	Ignored  string    `csv:"-" json:"-"`
+}
+
+func TestSelectRows_CSV(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/test.csv", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPost)
+		testFormValues(t, r, values{"select": "", "select-type": "2"})
+		// 记录可能跨帧
+		w.Write(testSelectResponse("name,age,score,ACTIVE,birthday,Ignored\nalice,30,1.5,true,2020-01-02T00:00:00Z,x\n\"b,ob\",4", "0,,false,,y\n"))
+	})
+
+	opt := &ObjectSelectOptions{
+		Expression:     "SELECT * FROM COSObject s",
+		ExpressionType: "SQL",
+		InputSerialization: &SelectInputSerialization{
+			CSV: &CSVInputSerialization{FileHeaderInfo: "NONE"},
+		},
+		OutputSerialization: &SelectOutputSerialization{
+			CSV: &CSVOutputSerialization{},
+		},
+		RequestProgress: "TRUE",
+	}
+	var progress, stats int
+	it, err := SelectRows[testSelectRow](context.Background(), client.Object, "test.csv", opt, &SelectRowsOptions{
+		HeaderRow: true,
+		OnProgress: func(f *ProgressFrame) {
+			progress += f.BytesReturned
+		},
+		OnStats: func(f *StatsFrame) {
+			stats += f.BytesReturned
+		},
+	})
+	if err != nil {
+		t.Fatalf("SelectRows returned error: %v", err)
+	}
+	defer it.Close()
+	var rows []testSelectRow
+	for it.Next() {
+		rows = append(rows, it.Row())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("SelectRows iteration error: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("SelectRows returned %d rows", len(rows))
+	}
+	if r := rows[0]; r.Name != "alice" || r.Age != 30 || r.Score == nil || *r.Score != 1.5 || !r.Active ||
+		!r.Birthday.Equal(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)) || r.Ignored != "" {
+		t.Errorf("SelectRows returned %+v", r)
+	}
+	if r := rows[1]; r.Name != "b,ob" || r.Age != 40 || r.Score != nil || r.Active {
+		t.Errorf("SelectRows returned %+v", r)
+	}
+	if progress != 5 || stats != 8 || it.Response().Frame.StatsFrame.BytesScanned != 20 {
+		t.Errorf("SelectRows frame callbacks: progress %v, stats %v", progress, stats)
+	}
+}
+
+func TestSelectRows_Columns(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/test.csv", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(testSelectResponse("alice|30\nbob|x\n"))
+	})
+	type row struct {
+		Name string `csv:"_1"`
+		Age  int    `csv:"_2"`
+	}
+	opt := &ObjectSelectOptions{
+		Expression:          "SELECT s._1, s._2 FROM COSObject s",
+		ExpressionType:      "SQL",
+		OutputSerialization: &SelectOutputSerialization{CSV: &CSVOutputSerialization{FieldDelimiter: "|"}},
+	}
+	it, err := SelectRows[row](context.Background(), client.Object, "test.csv", opt, nil)
+	if err != nil {
+		t.Fatalf("SelectRows returned error: %v", err)
+	}
+	defer it.Close()
+	if !it.Next() || it.Row().Name != "alice" || it.Row().Age != 30 {
+		t.Fatalf("SelectRows returned %+v, err: %v", it.Row(), it.Err())
+	}
+	if it.Next() || it.Err() == nil {
+		t.Errorf("SelectRows expect decode error")
+	}
+	if it.Next() {
+		t.Errorf("SelectRows expect end of iteration")
+	}
+}
+
+func TestSelectRows_JSON(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/test.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(testSelectResponse(`{"name":"alice","age":30,"active":true}`+"\n"+`{"name":"bob",`, `"age":40}`+"\n\n"))
+	})
+	opt := &ObjectSelectOptions{
+		Expression:          "SELECT * FROM COSObject s",
+		ExpressionType:      "SQL",
+		InputSerialization:  &SelectInputSerialization{JSON: &JSONInputSerialization{Type: "LINES"}},
+		OutputSerialization: &SelectOutputSerialization{JSON: &JSONOutputSerialization{}},
+	}
+	it, err := SelectRows[testSelectRow](context.Background(), client.Object, "test.json", opt, nil)
+	if err != nil {
+		t.Fatalf("SelectRows returned error: %v", err)
+	}
+	defer it.Close()
+	var names []string
+	for it.Next() {
+		names = append(names, it.Row().Name)
+	}
+	if it.Err() != nil || len(names) != 2 || names[0] != "alice" || names[1] != "bob" {
+		t.Errorf("SelectRows returned %v, err: %v", names, it.Err())
+	}
+}
+
+func TestSelectRows_Error(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/test.csv", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(testSelectEvent("Records", []byte("a,1\n")))
+		w.Write(testSelectFrame([][2]string{{":message-type", "error"}, {":error-code", "InvalidQuery"}, {":error-message", "bad"}}, nil))
+	})
+	out := &SelectOutputSerialization{CSV: &CSVOutputSerialization{}}
+	opt := &ObjectSelectOptions{OutputSerialization: out}
+	it, err := SelectRows[testSelectRow](context.Background(), client.Object, "test.csv", opt, &SelectRowsOptions{Columns: []string{"name", "age"}})
+	if err != nil {
+		t.Fatalf("SelectRows returned error: %v", err)
+	}
+	defer it.Close()
+	if !it.Next() || it.Row().Age != 1 {
+		t.Fatalf("SelectRows returned %+v, err: %v", it.Row(), it.Err())
+	}
+	if it.Next() {
+		t.Fatalf("SelectRows expect error")
+	}
+	if e, ok := it.Err().(*ErrorFrame); !ok || e.Code != "InvalidQuery" {
+		t.Errorf("SelectRows returned error: %v", it.Err())
+	}
+
+	if _, err = SelectRows[int](context.Background(), client.Object, "test.csv", opt, nil); err == nil {
+		t.Errorf("SelectRows expect error")
+	}
+	if _, err = SelectRows[testSelectRow](context.Background(), client.Object, "test.csv", &ObjectSelectOptions{}, nil); err == nil {
+		t.Errorf("SelectRows expect error")
+	}
+	out.CSV.RecordDelimiter = ";"
+	if _, err = SelectRows[testSelectRow](context.Background(), client.Object, "test.csv", opt, nil); err == nil {
+		t.Errorf("SelectRows expect error")
+	}
+}
@@ -0,0 +1,233 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+)
+
+const defaultSelectParallelPartSize = 64 * 1024 * 1024
+
+// SelectParallelOptions 是 SelectParallel 的选项
+type SelectParallelOptions struct {
+	// 每个扫描范围的大小，单位为 MB，默认 64MB
+	PartSize int64
+	// 并发的 Select 请求数，默认为 1
+	ThreadPoolSize int
+	// 按扫描范围的顺序输出，否则按完成的先后输出
+	KeepOrder bool
+	// 收到 Progress 帧时回调，参数为所有扫描范围的累加值，需要开启 ObjectSelectOptions.RequestProgress
+	OnProgress func(frame *ProgressFrame)
+	// Head 请求的选项，用于获取对象大小
+	HeadOpt *ObjectHeadOptions
+}
+
+type selectPartResult struct {
+	index int
+	data  []byte
+	stats StatsFrame
+	err   error
+}
+
+// SelectParallelResponse 是 SelectParallel 合并后的输出
+type SelectParallelResponse struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	keepOrder bool
+	total     int
+	slots     chan struct{}
+	ready     []chan *selectPartResult
+	completed chan *selectPartResult
+
+	mu       sync.Mutex
+	stats    StatsFrame
+	progress []ProgressFrame
+	onProg   func(frame *ProgressFrame)
+
+	consumed int
+	cur      *bytes.Reader
+	err      error
+}
+
+// SelectParallel 将对象按 PartSize 切分为多个扫描范围（ScanRange），并发调用 Select 并合并输出。
+//
+// 每个扫描范围只返回起始位置在该范围内的记录，因此跨越边界的记录不会重复或丢失。
+// 各扫描范围的输出在内存中缓存，最多同时缓存 ThreadPoolSize 个范围。
+// 注意 LIMIT、聚合函数等作用于单个扫描范围，而不是整个对象。
+// 仅支持未压缩的 CSV 与 JSON LINES 对象。
+func (s *ObjectService) SelectParallel(ctx context.Context, name string, opt *ObjectSelectOptions, popt *SelectParallelOptions) (*SelectParallelResponse, error) {
+	if opt == nil {
+		return nil, errors.New("ObjectSelectOptions is nil")
+	}
+	if opt.ScanRange != nil {
+		return nil, errors.New("SelectParallel doesn't support ScanRange Options")
+	}
+	if in := opt.InputSerialization; in != nil {
+		if in.CompressionType != "" && !strings.EqualFold(in.CompressionType, "NONE") {
+			return nil, fmt.Errorf("SelectParallel doesn't support CompressionType: %v", in.CompressionType)
+		}
+		if in.JSON != nil && !strings.EqualFold(in.JSON.Type, "LINES") {
+			return nil, fmt.Errorf("SelectParallel doesn't support JSON Type: %v", in.JSON.Type)
+		}
+		if in.CSV != nil && strings.EqualFold(in.CSV.AllowQuotedRecordDelimiter, "TRUE") {
+			return nil, errors.New("SelectParallel doesn't support AllowQuotedRecordDelimiter")
+		}
+	}
+	if popt == nil {
+		popt = &SelectParallelOptions{}
+	}
+	meta, _, err := s.Stat(ctx, name, popt.HeadOpt)
+	if err != nil {
+		return nil, err
+	}
+	partSize := popt.PartSize * 1024 * 1024
+	if partSize <= 0 {
+		partSize = defaultSelectParallelPartSize
+	}
+	var ranges []SelectScanRange
+	for start := int64(0); start < meta.ContentLength; start += partSize {
+		end := start + partSize - 1
+		if end >= meta.ContentLength {
+			end = meta.ContentLength - 1
+		}
+		ranges = append(ranges, SelectScanRange{Start: start, End: end})
+	}
+	poolSize := popt.ThreadPoolSize
+	if poolSize <= 0 {
+		poolSize = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	r := &SelectParallelResponse{
+		ctx:       ctx,
+		cancel:    cancel,
+		keepOrder: popt.KeepOrder,
+		total:     len(ranges),
+		slots:     make(chan struct{}, poolSize),
+		ready:     make([]chan *selectPartResult, len(ranges)),
+		completed: make(chan *selectPartResult, len(ranges)),
+		progress:  make([]ProgressFrame, len(ranges)),
+		onProg:    popt.OnProgress,
+	}
+	for i := range r.ready {
+		r.ready[i] = make(chan *selectPartResult, 1)
+	}
+	go func() {
+		for i := range ranges {
+			// 输出被读取后才释放 slot，限制缓存的范围个数
+			select {
+			case r.slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			go r.selectPart(s, name, opt, i, ranges[i])
+		}
+	}()
+	return r, nil
+}
+
+func (r *SelectParallelResponse) selectPart(s *ObjectService, name string, opt *ObjectSelectOptions, index int, scanRange SelectScanRange) {
+	res := &selectPartResult{index: index}
+	sopt := *opt
+	sopt.ScanRange = &scanRange
+	rc, err := s.Select(r.ctx, name, &sopt)
+	if err == nil {
+		resp := rc.(*ObjectSelectResponse)
+		resp.OnProgress = func(frame *ProgressFrame) {
+			r.updateProgress(index, frame)
+		}
+		resp.OnStats = func(frame *StatsFrame) {
+			res.stats = *frame
+		}
+		res.data, err = ioutil.ReadAll(resp)
+		resp.Close()
+	}
+	res.err = err
+	r.ready[index] <- res
+	r.completed <- res
+}
+
+func (r *SelectParallelResponse) updateProgress(index int, frame *ProgressFrame) {
+	if r.onProg == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.progress[index] = *frame
+	var sum ProgressFrame
+	for _, p := range r.progress {
+		sum.BytesScanned += p.BytesScanned
+		sum.BytesProcessed += p.BytesProcessed
+		sum.BytesReturned += p.BytesReturned
+	}
+	r.onProg(&sum)
+}
+
+// nextPart 等待下一个扫描范围的输出
+func (r *SelectParallelResponse) nextPart() (*selectPartResult, error) {
+	var res *selectPartResult
+	if r.keepOrder {
+		select {
+		case res = <-r.ready[r.consumed]:
+		case <-r.ctx.Done():
+			return nil, r.ctx.Err()
+		}
+	} else {
+		select {
+		case res = <-r.completed:
+		case <-r.ctx.Done():
+			return nil, r.ctx.Err()
+		}
+	}
+	r.consumed++
+	return res, res.err
+}
+
+func (r *SelectParallelResponse) Read(p []byte) (int, error) {
+	for {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.cur != nil && r.cur.Len() > 0 {
+			return r.cur.Read(p)
+		}
+		if r.cur != nil {
+			r.cur = nil
+			<-r.slots
+		}
+		if r.consumed == r.total {
+			r.err = io.EOF
+			continue
+		}
+		res, err := r.nextPart()
+		if err != nil {
+			r.err = err
+			r.cancel()
+			continue
+		}
+		r.mu.Lock()
+		r.stats.BytesScanned += res.stats.BytesScanned
+		r.stats.BytesProcessed += res.stats.BytesProcessed
+		r.stats.BytesReturned += res.stats.BytesReturned
+		r.mu.Unlock()
+		r.cur = bytes.NewReader(res.data)
+	}
+}
+
+// Stats 返回已读取的扫描范围的 Stats 累加值，读取到 EOF 后为整个对象的统计
+func (r *SelectParallelResponse) Stats() StatsFrame {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}
+
+// Close 取消未完成的 Select 请求
+func (r *SelectParallelResponse) Close() error {
+	r.cancel()
+	return nil
+}
@@ -0,0 +1,198 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testSelectFrame(headers [][2]string, payload []byte) []byte {
+	var hb bytes.Buffer
+	for _, h := range headers {
+		hb.WriteByte(byte(len(h[0])))
+		hb.WriteString(h[0])
+		hb.WriteByte(7)
+		binary.Write(&hb, binary.BigEndian, uint16(len(h[1])))
+		hb.WriteString(h[1])
+	}
+	var b bytes.Buffer
+	binary.Write(&b, binary.BigEndian, uint32(16+hb.Len()+len(payload)))
+	binary.Write(&b, binary.BigEndian, uint32(hb.Len()))
+	binary.Write(&b, binary.BigEndian, crc32.ChecksumIEEE(b.Bytes()))
+	b.Write(hb.Bytes())
+	b.Write(payload)
+	binary.Write(&b, binary.BigEndian, crc32.ChecksumIEEE(b.Bytes()))
+	return b.Bytes()
+}
+
+func testSelectEvent(event string, payload []byte) []byte {
+	return testSelectFrame([][2]string{{":message-type", "event"}, {":event-type", event}}, payload)
+}
+
+func testSelectResponse(records ...string) []byte {
+	var b bytes.Buffer
+	for _, r := range records {
+		b.Write(testSelectEvent("Records", []byte(r)))
+	}
+	b.Write(testSelectEvent("Progress", []byte("<Progress><BytesScanned>10</BytesScanned><BytesProcessed>10</BytesProcessed><BytesReturned>5</BytesReturned></Progress>")))
+	b.Write(testSelectEvent("Stats", []byte("<Stats><BytesScanned>20</BytesScanned><BytesProcessed>20</BytesProcessed><BytesReturned>8</BytesReturned></Stats>")))
+	b.Write(testSelectEvent("End", nil))
+	return b.Bytes()
+}
+
+func TestObjectService_SelectParallel(t *testing.T) {
+	setup()
+	defer teardown()
+
+	size := int64(3*1024*1024 + 100)
+	var mu sync.Mutex
+	var scanRanges []SelectScanRange
+	mux.HandleFunc("/test.csv", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+			return
+		}
+		testMethod(t, r, http.MethodPost)
+		body, _ := ioutil.ReadAll(r.Body)
+		var opt ObjectSelectOptions
+		if err := xml.Unmarshal(body, &opt); err != nil || opt.ScanRange == nil {
+			t.Errorf("SelectParallel request body error: %v, %+v", err, opt)
+			return
+		}
+		// 缺少 Start 时服务端按对象末尾的 End 个字节处理
+		if !bytes.Contains(body, []byte("<Start>")) {
+			t.Errorf("SelectParallel ScanRange without Start: %s", body)
+			return
+		}
+		mu.Lock()
+		scanRanges = append(scanRanges, *opt.ScanRange)
+		mu.Unlock()
+		idx := opt.ScanRange.Start / (1024 * 1024)
+		// 前面的范围返回得更慢
+		time.Sleep(time.Duration(4-idx) * 10 * time.Millisecond)
+		scanned := opt.ScanRange.End - opt.ScanRange.Start + 1
+		w.Write(testSelectEvent("Records", []byte(fmt.Sprintf("r%d,1\n", idx))))
+		w.Write(testSelectEvent("Progress", []byte(fmt.Sprintf("<Progress><BytesScanned>%d</BytesScanned></Progress>", scanned))))
+		w.Write(testSelectEvent("Records", []byte(fmt.Sprintf("r%d,2\n", idx))))
+		w.Write(testSelectEvent("Stats", []byte(fmt.Sprintf("<Stats><BytesScanned>%d</BytesScanned><BytesReturned>10</BytesReturned></Stats>", scanned))))
+		w.Write(testSelectEvent("End", nil))
+	})
+
+	opt := &ObjectSelectOptions{
+		Expression:          "SELECT * FROM COSObject s",
+		ExpressionType:      "SQL",
+		InputSerialization:  &SelectInputSerialization{CSV: &CSVInputSerialization{}},
+		OutputSerialization: &SelectOutputSerialization{CSV: &CSVOutputSerialization{}},
+		RequestProgress:     "TRUE",
+	}
+	var progress int
+	resp, err := client.Object.SelectParallel(context.Background(), "test.csv", opt, &SelectParallelOptions{
+		PartSize:       1,
+		ThreadPoolSize: 4,
+		KeepOrder:      true,
+		OnProgress: func(f *ProgressFrame) {
+			progress = f.BytesScanned
+		},
+	})
+	if err != nil {
+		t.Fatalf("Object.SelectParallel returned error: %v", err)
+	}
+	data, err := ioutil.ReadAll(resp)
+	resp.Close()
+	if err != nil {
+		t.Fatalf("Object.SelectParallel read error: %v", err)
+	}
+	want := "r0,1\nr0,2\nr1,1\nr1,2\nr2,1\nr2,2\nr3,1\nr3,2\n"
+	if string(data) != want {
+		t.Errorf("Object.SelectParallel returned %q, want %q", data, want)
+	}
+	if st := resp.Stats(); st.BytesScanned != int(size) || st.BytesReturned != 40 {
+		t.Errorf("Object.SelectParallel stats: %+v", st)
+	}
+	if progress != int(size) {
+		t.Errorf("Object.SelectParallel progress: %v", progress)
+	}
+	if len(scanRanges) != 4 {
+		t.Fatalf("Object.SelectParallel sent %d requests", len(scanRanges))
+	}
+	for _, r := range scanRanges {
+		idx := r.Start / (1024 * 1024)
+		end := (idx+1)*1024*1024 - 1
+		if idx == 3 {
+			end = size - 1
+		}
+		if r.Start != idx*1024*1024 || r.End != end {
+			t.Errorf("Object.SelectParallel unexpected scan range: %+v", r)
+		}
+	}
+
+	// 不保证顺序，但记录不会丢失或者交错
+	resp, err = client.Object.SelectParallel(context.Background(), "test.csv", opt, &SelectParallelOptions{PartSize: 1, ThreadPoolSize: 2})
+	if err != nil {
+		t.Fatalf("Object.SelectParallel returned error: %v", err)
+	}
+	data, err = ioutil.ReadAll(resp)
+	if err != nil || len(data) != len(want) {
+		t.Fatalf("Object.SelectParallel returned %q, err: %v", data, err)
+	}
+	for i := 0; i < 4; i++ {
+		if !bytes.Contains(data, []byte(fmt.Sprintf("r%d,1\nr%d,2\n", i, i))) {
+			t.Errorf("Object.SelectParallel returned %q", data)
+		}
+	}
+}
+
+func TestObjectService_SelectParallel_Error(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/test.csv", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.Header().Set("Content-Length", strconv.Itoa(2*1024*1024))
+			return
+		}
+		var opt ObjectSelectOptions
+		xml.NewDecoder(r.Body).Decode(&opt)
+		if opt.ScanRange.Start > 0 {
+			w.Write(testSelectFrame([][2]string{{":message-type", "error"}, {":error-code", "InvalidQuery"}, {":error-message", "bad"}}, nil))
+			return
+		}
+		w.Write(testSelectEvent("Records", []byte("a\n")))
+		w.Write(testSelectEvent("End", nil))
+	})
+	opt := &ObjectSelectOptions{OutputSerialization: &SelectOutputSerialization{CSV: &CSVOutputSerialization{}}}
+	resp, err := client.Object.SelectParallel(context.Background(), "test.csv", opt, &SelectParallelOptions{PartSize: 1, KeepOrder: true})
+	if err != nil {
+		t.Fatalf("Object.SelectParallel returned error: %v", err)
+	}
+	defer resp.Close()
+	data, err := ioutil.ReadAll(resp)
+	if e, ok := err.(*ErrorFrame); !ok || e.Code != "InvalidQuery" || string(data) != "a\n" {
+		t.Errorf("Object.SelectParallel returned %q, err: %v", data, err)
+	}
+
+	invalid := []*ObjectSelectOptions{
+		nil,
+		{ScanRange: &SelectScanRange{Start: 1}},
+		{InputSerialization: &SelectInputSerialization{CompressionType: "GZIP"}},
+		{InputSerialization: &SelectInputSerialization{JSON: &JSONInputSerialization{Type: "DOCUMENT"}}},
+		{InputSerialization: &SelectInputSerialization{CSV: &CSVInputSerialization{AllowQuotedRecordDelimiter: "TRUE"}}},
+	}
+	for i, o := range invalid {
+		if _, err := client.Object.SelectParallel(context.Background(), "test.csv", o, nil); err == nil {
+			t.Errorf("case %d: Object.SelectParallel expect error", i)
+		}
+	}
+	if _, err := client.Object.SelectParallel(context.Background(), "notexist", opt, nil); err == nil {
+		t.Errorf("Object.SelectParallel expect error")
+	}
+}
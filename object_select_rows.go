@@ -0,0 +1,240 @@
+package cos
+
+import (
+	"bufio"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// SelectRowsOptions 是 SelectRows 的选项
+type SelectRowsOptions struct {
+	// CSV 输出各列的名称，与结构体字段的 csv tag（没有 tag 时为字段名，大小写不敏感）对应。
+	// 为空时各列的名称为 _1, _2, ...，与 Select 语句中的位置列一致
+	Columns []string
+	// CSV 输出的第一条记录为表头，优先级高于 Columns
+	HeaderRow bool
+	// 迭代过程中收到 Progress 帧时回调，需要开启 ObjectSelectOptions.RequestProgress
+	OnProgress func(frame *ProgressFrame)
+	// 迭代过程中收到 Stats 帧时回调
+	OnStats func(frame *StatsFrame)
+}
+
+// selectRowDecoder 将 Select 输出的一条记录解码到结构体中
+type selectRowDecoder interface {
+	decode(v reflect.Value) error
+}
+
+func newSelectRowDecoder(typ reflect.Type, r io.Reader, out *SelectOutputSerialization, opt *SelectRowsOptions) (selectRowDecoder, error) {
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("select row type must be a struct, got: %v", typ)
+	}
+	if out == nil || (out.CSV == nil && out.JSON == nil) {
+		return nil, errors.New("OutputSerialization is empty")
+	}
+	if out.JSON != nil {
+		return newSelectJSONDecoder(r, out.JSON.RecordDelimiter), nil
+	}
+	return newSelectCSVDecoder(typ, r, out.CSV, opt)
+}
+
+type selectJSONDecoder struct {
+	r     *bufio.Reader
+	delim string
+}
+
+func newSelectJSONDecoder(r io.Reader, delim string) *selectJSONDecoder {
+	if delim == "" {
+		delim = "\n"
+	}
+	return &selectJSONDecoder{r: bufio.NewReader(r), delim: delim}
+}
+
+func (d *selectJSONDecoder) decode(v reflect.Value) error {
+	for {
+		rec, err := readSelectRecord(d.r, d.delim)
+		if len(strings.TrimSpace(rec)) > 0 {
+			if e := json.Unmarshal([]byte(rec), v.Addr().Interface()); e != nil {
+				return fmt.Errorf("decode json record failed: %v, record: %q", e, rec)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readSelectRecord 读取以 delim 结尾的一条记录，返回的记录不包含 delim
+func readSelectRecord(r *bufio.Reader, delim string) (string, error) {
+	var sb strings.Builder
+	last := delim[len(delim)-1]
+	for {
+		line, err := r.ReadString(last)
+		sb.WriteString(line)
+		if err != nil {
+			return sb.String(), err
+		}
+		if strings.HasSuffix(sb.String(), delim) {
+			return strings.TrimSuffix(sb.String(), delim), nil
+		}
+	}
+}
+
+type selectCSVDecoder struct {
+	r         *csv.Reader
+	fields    map[string][]int
+	columns   []string
+	headerRow bool
+}
+
+func newSelectCSVDecoder(typ reflect.Type, r io.Reader, out *CSVOutputSerialization, opt *SelectRowsOptions) (*selectCSVDecoder, error) {
+	switch out.RecordDelimiter {
+	case "", "\n", "\r\n":
+	default:
+		return nil, fmt.Errorf("unsupported csv RecordDelimiter: %q", out.RecordDelimiter)
+	}
+	if out.QuoteCharacter != "" && out.QuoteCharacter != `"` {
+		return nil, fmt.Errorf("unsupported csv QuoteCharacter: %q", out.QuoteCharacter)
+	}
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	if out.FieldDelimiter != "" {
+		c, size := utf8.DecodeRuneInString(out.FieldDelimiter)
+		if size != len(out.FieldDelimiter) {
+			return nil, fmt.Errorf("unsupported csv FieldDelimiter: %q", out.FieldDelimiter)
+		}
+		cr.Comma = c
+	}
+	d := &selectCSVDecoder{
+		r:      cr,
+		fields: make(map[string][]int),
+	}
+	if opt != nil {
+		d.columns = opt.Columns
+		d.headerRow = opt.HeaderRow
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		if tag := f.Tag.Get("csv"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			name = strings.Split(tag, ",")[0]
+		}
+		d.fields[strings.ToLower(name)] = f.Index
+	}
+	return d, nil
+}
+
+func (d *selectCSVDecoder) decode(v reflect.Value) error {
+	rec, err := d.r.Read()
+	if err != nil {
+		return err
+	}
+	if d.headerRow {
+		d.headerRow = false
+		d.columns = append([]string(nil), rec...)
+		if rec, err = d.r.Read(); err != nil {
+			return err
+		}
+	}
+	for i, value := range rec {
+		name := "_" + strconv.Itoa(i+1)
+		if i < len(d.columns) {
+			name = d.columns[i]
+		}
+		idx, ok := d.fields[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			continue
+		}
+		if err := setSelectField(v.FieldByIndex(idx), value); err != nil {
+			return fmt.Errorf("decode csv column %v failed: %v", name, err)
+		}
+	}
+	return nil
+}
+
+var (
+	selectTimeType            = reflect.TypeOf(time.Time{})
+	selectTextUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func setSelectField(f reflect.Value, s string) error {
+	if f.Kind() == reflect.Ptr {
+		if s == "" {
+			f.Set(reflect.Zero(f.Type()))
+			return nil
+		}
+		if f.IsNil() {
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+		f = f.Elem()
+	}
+	if f.Type() == selectTimeType {
+		if s == "" {
+			f.Set(reflect.Zero(f.Type()))
+			return nil
+		}
+		t, err := ParseObjectTime(s)
+		if err != nil {
+			return err
+		}
+		f.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if f.CanAddr() && f.Addr().Type().Implements(selectTextUnmarshalerType) {
+		return f.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if f.Kind() == reflect.String {
+		f.SetString(s)
+		return nil
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		f.Set(reflect.Zero(f.Type()))
+		return nil
+	}
+	switch f.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported field type: %v", f.Type())
+	}
+	return nil
+}
@@ -2543,6 +2543,134 @@ func TestObjectService_PutFromURL(t *testing.T) {
 
 }
 
+func TestObjectService_PutFromURL_Ranges(t *testing.T) {
+	setup()
+	defer teardown()
+
+	data := make([]byte, 1024*1024*5+133)
+	rand.Read(data)
+
+	var mu sync.Mutex
+	rangeFailed := map[string]bool{}
+	mux.HandleFunc("/source", func(w http.ResponseWriter, r *http.Request) {
+		testHeader(t, r, "Authorization", "Bearer token")
+		rg := r.Header.Get("Range")
+		mu.Lock()
+		// 每个分块第一次请求失败，验证单个分块的重试
+		failed := rg != "bytes=0-0" && !rangeFailed[rg]
+		rangeFailed[rg] = true
+		mu.Unlock()
+		if failed {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if rg != "bytes=0-0" && r.Header.Get("If-Range") != `"v1"` {
+			t.Errorf("range %v If-Range: %v", rg, r.Header.Get("If-Range"))
+		}
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	})
+	parts := map[int][]byte{}
+	mux.HandleFunc("/dest", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch {
+		case r.Method == http.MethodPost && r.Form.Get("uploadId") == "":
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>putfromurl_uploadid</UploadId></InitiateMultipartUploadResult>`)
+		case r.Method == http.MethodPut:
+			bs, _ := ioutil.ReadAll(r.Body)
+			n, _ := strconv.Atoi(r.Form.Get("partNumber"))
+			mu.Lock()
+			parts[n] = bs
+			mu.Unlock()
+			w.Header().Add("x-cos-hash-crc64ecma", strconv.FormatUint(crc64.Checksum(bs, crc64.MakeTable(crc64.ECMA)), 10))
+			w.Header().Add("ETag", "\""+hex.EncodeToString(calMD5Digest(bs))+"\"")
+		case r.Method == http.MethodPost:
+			body := &CompleteMultipartUploadOptions{}
+			xml.NewDecoder(r.Body).Decode(body)
+			var got []byte
+			for i, p := range body.Parts {
+				if p.PartNumber != i+1 {
+					t.Errorf("complete parts not sorted: %+v", body.Parts)
+				}
+				got = append(got, parts[p.PartNumber]...)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("uploaded data mismatch")
+			}
+			fmt.Fprint(w, `<CompleteMultipartUploadResult><Key>dest</Key><ETag>&quot;etag&quot;</ETag></CompleteMultipartUploadResult>`)
+		default:
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL)
+		}
+	})
+
+	opt := &ObjectPutFromURLOptions{
+		PartSize:       1,
+		ThreadPoolSize: 3,
+		SourceHeader:   http.Header{"Authorization": {"Bearer token"}},
+		SourceClient:   &http.Client{Timeout: 10 * time.Second},
+		RangeTimeout:   5 * time.Second,
+	}
+	start := time.Now()
+	_, _, err := client.Object.PutFromURL(context.Background(), "dest", server.URL+"/source", opt)
+	if err != nil {
+		t.Fatalf("Object.PutFromURL returned error: %v", err)
+	}
+	if len(parts) != 6 {
+		t.Errorf("Object.PutFromURL uploaded %v parts, want 6", len(parts))
+	}
+	// 分块重试前等待退避间隔
+	if d := time.Since(start); d < defaultRangeRetryInterval {
+		t.Errorf("Object.PutFromURL retried ranges without backoff, took %v", d)
+	}
+
+	// 分块重试次数用完
+	opt.RetryTimes = 1
+	rangeFailed = map[string]bool{}
+	var aborted bool
+	mux.HandleFunc("/dest2", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.Method {
+		case http.MethodPost:
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>putfromurl_uploadid</UploadId></InitiateMultipartUploadResult>`)
+		case http.MethodDelete:
+			aborted = true
+		}
+	})
+	_, _, err = client.Object.PutFromURL(context.Background(), "dest2", server.URL+"/source", opt)
+	if err == nil || !aborted {
+		t.Errorf("Object.PutFromURL expect error and abort, error: %v, aborted: %v", err, aborted)
+	}
+
+	// 探测后源站对象发生变化，If-Range 不匹配时返回整个对象，不重试
+	opt.RetryTimes = 3
+	aborted = false
+	var requested []string
+	mux.HandleFunc("/changing", func(w http.ResponseWriter, r *http.Request) {
+		rg := r.Header.Get("Range")
+		mu.Lock()
+		etag := `"v2"`
+		if rg == "bytes=0-0" {
+			etag = `"v1"`
+		} else {
+			requested = append(requested, rg)
+		}
+		mu.Unlock()
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	})
+	_, _, err = client.Object.PutFromURL(context.Background(), "dest2", server.URL+"/changing", opt)
+	if !errors.Is(err, ErrSourceChanged) || !aborted {
+		t.Errorf("Object.PutFromURL expect ErrSourceChanged and abort, error: %v, aborted: %v", err, aborted)
+	}
+	seen := map[string]bool{}
+	for _, rg := range requested {
+		if seen[rg] {
+			t.Errorf("range %v retried after source changed", rg)
+		}
+		seen[rg] = true
+	}
+}
+
 func TestObjectService_UploadWithPicOperations(t *testing.T) {
 	setup()
 	defer teardown()
@@ -0,0 +1,155 @@
+package cos
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// UploadSkipStrategy 判断本地文件与目标对象是否一致，一致时跳过上传
+type UploadSkipStrategy interface {
+	Unchanged(filePath string, size int64, meta *ObjectMeta) (bool, error)
+}
+
+// UploadSkipMetaRecorder 是 UploadSkipStrategy 的可选接口，上传时写入比较所需的自定义元数据
+type UploadSkipMetaRecorder interface {
+	RecordMeta(filePath string, meta *http.Header) error
+}
+
+// CRC64SkipStrategy 比较对象长度和 x-cos-hash-crc64ecma
+type CRC64SkipStrategy struct{}
+
+func (CRC64SkipStrategy) Unchanged(filePath string, size int64, meta *ObjectMeta) (bool, error) {
+	if !meta.HasCRC64 || meta.Size != size {
+		return false, nil
+	}
+	fd, err := os.Open(filePath)
+	if err != nil {
+		return false, err
+	}
+	defer fd.Close()
+	crc, err := calCRC64(fd)
+	if err != nil {
+		return false, err
+	}
+	return crc == meta.CRC64, nil
+}
+
+// ETagMD5SkipStrategy 比较对象长度和 ETag，仅适用于简单上传的对象，分块上传的对象 ETag 不是 MD5
+type ETagMD5SkipStrategy struct{}
+
+func (ETagMD5SkipStrategy) Unchanged(filePath string, size int64, meta *ObjectMeta) (bool, error) {
+	etag := strings.Trim(meta.ETag, "\"")
+	if meta.Size != size || len(etag) != md5.Size*2 {
+		return false, nil
+	}
+	sum, err := hashFile(filePath, md5.New())
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(sum, etag), nil
+}
+
+// MetaHashSkipStrategy 比较本地文件的摘要和 x-cos-meta-<Key>，上传时会写入该自定义头部。
+// New 为空时使用 SHA256。
+type MetaHashSkipStrategy struct {
+	Key string
+	New func() hash.Hash
+}
+
+func (m *MetaHashSkipStrategy) hash(filePath string) (string, error) {
+	if m.Key == "" {
+		return "", fmt.Errorf("MetaHashSkipStrategy Key is empty")
+	}
+	newHash := m.New
+	if newHash == nil {
+		newHash = sha256.New
+	}
+	return hashFile(filePath, newHash())
+}
+
+func (m *MetaHashSkipStrategy) Unchanged(filePath string, size int64, meta *ObjectMeta) (bool, error) {
+	want, ok := meta.Meta[strings.ToLower(m.Key)]
+	if !ok || meta.Size != size {
+		return false, nil
+	}
+	sum, err := m.hash(filePath)
+	if err != nil {
+		return false, err
+	}
+	return sum == want, nil
+}
+
+func (m *MetaHashSkipStrategy) RecordMeta(filePath string, meta *http.Header) error {
+	sum, err := m.hash(filePath)
+	if err != nil {
+		return err
+	}
+	meta.Set("x-cos-meta-"+m.Key, sum)
+	return nil
+}
+
+func hashFile(filePath string, h hash.Hash) (string, error) {
+	fd, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer fd.Close()
+	if _, err = io.Copy(h, fd); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// checkUploadSkip Head 目标对象并按 strategy 比较，对象不存在时不跳过，Head 的其他错误直接返回；
+// 不跳过时按 UploadSkipMetaRecorder 在 opt 中写入比较所需的自定义元数据。
+func (s *ObjectService) checkUploadSkip(ctx context.Context, name, filePath string, strategy UploadSkipStrategy, opt *ObjectPutHeaderOptions) (*Response, bool, error) {
+	stat, err := os.Stat(filePath)
+	if err != nil {
+		return nil, false, err
+	}
+	headOpt := &ObjectHeadOptions{
+		XCosSSECustomerAglo:   opt.XCosSSECustomerAglo,
+		XCosSSECustomerKey:    opt.XCosSSECustomerKey,
+		XCosSSECustomerKeyMD5: opt.XCosSSECustomerKeyMD5,
+	}
+	meta, resp, err := s.Stat(ctx, name, headOpt)
+	if err != nil && !IsNotFoundError(err) {
+		return resp, false, err
+	}
+	if err == nil {
+		unchanged, err := strategy.Unchanged(filePath, stat.Size(), meta)
+		if err != nil {
+			return nil, false, err
+		}
+		if unchanged {
+			return resp, true, nil
+		}
+	}
+	if recorder, ok := strategy.(UploadSkipMetaRecorder); ok {
+		if opt.XCosMetaXXX == nil {
+			opt.XCosMetaXXX = &http.Header{}
+		}
+		if err := recorder.RecordMeta(filePath, opt.XCosMetaXXX); err != nil {
+			return nil, false, err
+		}
+	}
+	return nil, false, nil
+}
+
+// skippedUploadResult 使用目标对象的 Head 响应构造跳过上传时的结果
+func (s *ObjectService) skippedUploadResult(name string, resp *Response) *CompleteMultipartUploadResult {
+	return &CompleteMultipartUploadResult{
+		Location: fmt.Sprintf("%s/%s", s.client.BaseURL.BucketURL, name),
+		Key:      name,
+		ETag:     resp.Header.Get("ETag"),
+		Skipped:  true,
+	}
+}
@@ -0,0 +1,119 @@
+package cos
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"hash/crc64"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestObjectService_Upload_SkipIfUnchanged(t *testing.T) {
+	setup()
+	defer teardown()
+
+	data := []byte("unchanged artifact")
+	filePath := "tmpfile" + time.Now().Format(time.RFC3339)
+	ioutil.WriteFile(filePath, data, 0644)
+	defer os.Remove(filePath)
+
+	md5sum := md5.Sum(data)
+	header := http.Header{}
+	var puts int
+	mux.HandleFunc("/artifact", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodHead:
+			if len(header) == 0 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+		case http.MethodPut:
+			puts++
+			b, _ := ioutil.ReadAll(r.Body)
+			w.Header().Set("x-cos-hash-crc64ecma", strconv.FormatUint(crc64.Checksum(b, crc64.MakeTable(crc64.ECMA)), 10))
+			header = http.Header{}
+			header.Set("Content-Length", strconv.Itoa(len(b)))
+			header.Set("x-cos-hash-crc64ecma", w.Header().Get("x-cos-hash-crc64ecma"))
+			header.Set("ETag", "\""+hex.EncodeToString(md5sum[:])+"\"")
+			for k, v := range r.Header {
+				if strings.HasPrefix(k, "X-Cos-Meta-") {
+					header[k] = v
+				}
+			}
+		}
+	})
+
+	strategies := []UploadSkipStrategy{
+		CRC64SkipStrategy{},
+		ETagMD5SkipStrategy{},
+		&MetaHashSkipStrategy{Key: "sha1", New: sha1.New},
+	}
+	for _, strategy := range strategies {
+		header = http.Header{}
+		puts = 0
+		for i := 0; i < 2; i++ {
+			res, rsp, err := client.Object.Upload(context.Background(), "artifact", filePath, &MultiUploadOptions{
+				SkipIfUnchanged: strategy,
+			})
+			if err != nil {
+				t.Fatalf("Object.Upload returned error: %v", err)
+			}
+			if res.Skipped != (i == 1) || rsp == nil {
+				t.Errorf("%T Object.Upload round %v Skipped: %v", strategy, i, res.Skipped)
+			}
+		}
+		if puts != 1 {
+			t.Errorf("%T Object.Upload put %v times, want 1", strategy, puts)
+		}
+
+		res, _, err := client.Object.PutFromFileWithResult(context.Background(), "artifact", filePath, &ObjectPutOptions{
+			ObjectPutHeaderOptions: &ObjectPutHeaderOptions{SkipIfUnchanged: strategy},
+		})
+		if err != nil {
+			t.Fatalf("Object.PutFromFileWithResult returned error: %v", err)
+		}
+		if !res.Skipped || puts != 1 {
+			t.Errorf("%T Object.PutFromFile expect skipped", strategy)
+		}
+	}
+
+	// 内容变化后重新上传
+	header.Set("x-cos-hash-crc64ecma", "1")
+	res, _, err := client.Object.PutFromFileWithResult(context.Background(), "artifact", filePath, &ObjectPutOptions{
+		ObjectPutHeaderOptions: &ObjectPutHeaderOptions{SkipIfUnchanged: CRC64SkipStrategy{}},
+	})
+	if err != nil {
+		t.Fatalf("Object.PutFromFileWithResult returned error: %v", err)
+	}
+	if res.Skipped || puts != 2 {
+		t.Errorf("Object.PutFromFileWithResult with changed crc64 expect upload")
+	}
+
+	// Head 返回 404 以外的错误时不上传
+	mux.HandleFunc("/denied", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("unexpected %v request after Head failed", r.Method)
+		}
+		w.WriteHeader(http.StatusForbidden)
+	})
+	if _, err = client.Object.PutFromFile(context.Background(), "denied", filePath, &ObjectPutOptions{
+		ObjectPutHeaderOptions: &ObjectPutHeaderOptions{SkipIfUnchanged: CRC64SkipStrategy{}},
+	}); !IsAuthError(err) {
+		t.Errorf("Object.PutFromFile with denied Head returned error: %v", err)
+	}
+	if _, _, err = client.Object.Upload(context.Background(), "denied", filePath, &MultiUploadOptions{
+		SkipIfUnchanged: CRC64SkipStrategy{},
+	}); !IsAuthError(err) {
+		t.Errorf("Object.Upload with denied Head returned error: %v", err)
+	}
+}
@@ -0,0 +1,336 @@
+package cos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ObjectVersion 是对象的一个历史版本或者删除标记
+type ObjectVersion struct {
+	Key       string
+	VersionId string
+	IsLatest  bool
+	// 删除标记没有 ETag、Size 和 StorageClass
+	IsDeleteMarker bool
+	LastModified   time.Time
+	ETag           string
+	Size           int64
+	StorageClass   string
+	Owner          *Owner
+}
+
+// ObjectVersionHistory 是一个对象的版本历史，Versions 按修改时间从新到旧排列
+type ObjectVersionHistory struct {
+	Key      string
+	Versions []ObjectVersion
+}
+
+// Latest 返回当前版本，历史为空时返回 nil
+func (h *ObjectVersionHistory) Latest() *ObjectVersion {
+	for i := range h.Versions {
+		if h.Versions[i].IsLatest {
+			return &h.Versions[i]
+		}
+	}
+	if len(h.Versions) > 0 {
+		return &h.Versions[0]
+	}
+	return nil
+}
+
+// Deleted 对象当前是否已被删除，即当前版本为删除标记
+func (h *ObjectVersionHistory) Deleted() bool {
+	latest := h.Latest()
+	return latest != nil && latest.IsDeleteMarker
+}
+
+// Find 按版本号查找，不存在时返回 nil
+func (h *ObjectVersionHistory) Find(versionId string) *ObjectVersion {
+	for i := range h.Versions {
+		if h.Versions[i].VersionId == versionId {
+			return &h.Versions[i]
+		}
+	}
+	return nil
+}
+
+// Noncurrent 返回所有非当前版本（包括删除标记），从新到旧排列
+func (h *ObjectVersionHistory) Noncurrent() []ObjectVersion {
+	latest := h.Latest()
+	var res []ObjectVersion
+	for i := range h.Versions {
+		if &h.Versions[i] != latest {
+			res = append(res, h.Versions[i])
+		}
+	}
+	return res
+}
+
+func (h *ObjectVersionHistory) sort() {
+	// 同一时间内当前版本排在最前面
+	sort.SliceStable(h.Versions, func(i, j int) bool {
+		a, b := h.Versions[i], h.Versions[j]
+		if !a.LastModified.Equal(b.LastModified) {
+			return a.LastModified.After(b.LastModified)
+		}
+		return a.IsLatest && !b.IsLatest
+	})
+}
+
+// errStopWalk 由 walkObjectVersions 的回调返回，表示提前结束遍历
+var errStopWalk = errors.New("stop walking object versions")
+
+// walkObjectVersions 遍历 prefix 下所有对象的版本，每个对象的完整历史回调一次，按对象名有序
+func (c *Client) walkObjectVersions(ctx context.Context, prefix string, fn func(h *ObjectVersionHistory) error) error {
+	return c.walkObjectVersionsWithOptions(ctx, &BucketGetObjectVersionsOptions{Prefix: prefix}, fn)
+}
+
+// walkObjectVersionsWithOptions 同 walkObjectVersions，可以指定 Delimiter、MaxKeys 等列出参数
+func (c *Client) walkObjectVersionsWithOptions(ctx context.Context, o *BucketGetObjectVersionsOptions, fn func(h *ObjectVersionHistory) error) error {
+	opt := *o
+	opt.EncodingType = "url"
+	var cur *ObjectVersionHistory
+	add := func(v ObjectVersion) error {
+		if cur != nil && cur.Key != v.Key {
+			cur.sort()
+			if err := fn(cur); err != nil {
+				return err
+			}
+			cur = nil
+		}
+		if cur == nil {
+			cur = &ObjectVersionHistory{Key: v.Key}
+		}
+		cur.Versions = append(cur.Versions, v)
+		return nil
+	}
+	for {
+		res, _, err := c.Bucket.GetObjectVersions(ctx, &opt)
+		if err != nil {
+			return err
+		}
+		versions := make([]ObjectVersion, 0, len(res.Version)+len(res.DeleteMarker))
+		for _, v := range res.Version {
+			versions = append(versions, ObjectVersion{
+				Key:          v.Key,
+				VersionId:    v.VersionId,
+				IsLatest:     v.IsLatest,
+				ETag:         v.ETag,
+				Size:         v.Size,
+				StorageClass: v.StorageClass,
+				Owner:        v.Owner,
+				LastModified: parseVersionTime(v.LastModified),
+			})
+		}
+		for _, v := range res.DeleteMarker {
+			versions = append(versions, ObjectVersion{
+				Key:            v.Key,
+				VersionId:      v.VersionId,
+				IsLatest:       v.IsLatest,
+				IsDeleteMarker: true,
+				Owner:          v.Owner,
+				LastModified:   parseVersionTime(v.LastModified),
+			})
+		}
+		for i := range versions {
+			if res.EncodingType == "url" {
+				key, err := DecodeURIComponent(versions[i].Key)
+				if err != nil {
+					return err
+				}
+				versions[i].Key = key
+			}
+		}
+		// Version 和 DeleteMarker 分开返回，按对象名合并，同一对象内的顺序由 sort 保证
+		sort.SliceStable(versions, func(i, j int) bool {
+			return versions[i].Key < versions[j].Key
+		})
+		for _, v := range versions {
+			if err := add(v); err != nil {
+				return err
+			}
+		}
+		if !res.IsTruncated {
+			break
+		}
+		if res.NextKeyMarker == "" && res.NextVersionIdMarker == "" {
+			return errors.New("GetObjectVersions is truncated without next marker")
+		}
+		opt.KeyMarker = res.NextKeyMarker
+		opt.VersionIdMarker = res.NextVersionIdMarker
+	}
+	if cur != nil {
+		cur.sort()
+		return fn(cur)
+	}
+	return nil
+}
+
+func parseVersionTime(v string) time.Time {
+	t, _ := ParseObjectTime(v)
+	return t
+}
+
+// ListVersions 列出对象 key 的所有版本，包括删除标记，对象不存在任何版本时 Versions 为空
+func (s *ObjectService) ListVersions(ctx context.Context, key string) (*ObjectVersionHistory, error) {
+	if key == "" {
+		return nil, errors.New("empty object name")
+	}
+	res := &ObjectVersionHistory{Key: key}
+	// key 排在所有以它为前缀的对象之前，遇到第一个对象的历史即可结束；
+	// Delimiter 使 key/ 下的对象合并为 CommonPrefixes，不逐个列出
+	opt := &BucketGetObjectVersionsOptions{
+		Prefix:    key,
+		Delimiter: "/",
+		MaxKeys:   1000,
+	}
+	err := s.client.walkObjectVersionsWithOptions(ctx, opt, func(h *ObjectVersionHistory) error {
+		if h.Key == key {
+			res = h
+		}
+		return errStopWalk
+	})
+	if err != nil && err != errStopWalk {
+		return nil, err
+	}
+	return res, nil
+}
+
+// RestoreVersion 将对象 key 的历史版本 versionId 复制为当前版本，大于 5GB 的对象使用分块复制。
+// 恢复后会产生一个新的版本，原有版本均保留。
+func (s *ObjectService) RestoreVersion(ctx context.Context, key, versionId string, opt ...*MultiCopyOptions) (*ObjectCopyResult, *Response, error) {
+	if key == "" {
+		return nil, nil, errors.New("empty object name")
+	}
+	if versionId == "" {
+		return nil, nil, errors.New("empty version id")
+	}
+	var copyOpt *MultiCopyOptions
+	if len(opt) > 0 {
+		copyOpt = opt[0]
+	}
+	bucketURL := s.client.BaseURL.BucketURL
+	sourceURL := fmt.Sprintf("%s%s/%s", bucketURL.Host, basePath(bucketURL), key)
+	return s.MultiCopy(ctx, key, sourceURL, copyOpt, versionId)
+}
+
+// ObjectVersionChange 是两个版本之间一项元数据的差异
+type ObjectVersionChange struct {
+	Field string
+	From  string
+	To    string
+}
+
+// ObjectVersionDiff 是两个版本的元数据比较结果
+type ObjectVersionDiff struct {
+	From *ObjectMeta
+	To   *ObjectMeta
+	// 内容是否相同，按长度和 CRC64 判断，CRC64 缺失时按 ETag 判断
+	SameContent bool
+	Changes     []ObjectVersionChange
+}
+
+// DiffVersions 比较对象 key 的两个版本的内容摘要和元数据，版本号为空时表示当前版本
+func (s *ObjectService) DiffVersions(ctx context.Context, key, fromVersionId, toVersionId string) (*ObjectVersionDiff, error) {
+	stat := func(versionId string) (*ObjectMeta, error) {
+		var id []string
+		if versionId != "" {
+			id = append(id, versionId)
+		}
+		meta, _, err := s.Stat(ctx, key, nil, id...)
+		return meta, err
+	}
+	from, err := stat(fromVersionId)
+	if err != nil {
+		return nil, err
+	}
+	to, err := stat(toVersionId)
+	if err != nil {
+		return nil, err
+	}
+	diff := &ObjectVersionDiff{From: from, To: to}
+	if from.HasCRC64 && to.HasCRC64 {
+		diff.SameContent = from.Size == to.Size && from.CRC64 == to.CRC64
+	} else {
+		diff.SameContent = from.Size == to.Size && from.ETag == to.ETag
+	}
+	compare := func(field, a, b string) {
+		if a != b {
+			diff.Changes = append(diff.Changes, ObjectVersionChange{Field: field, From: a, To: b})
+		}
+	}
+	compare("Size", strconv.FormatInt(from.Size, 10), strconv.FormatInt(to.Size, 10))
+	compare("ETag", from.ETag, to.ETag)
+	if from.HasCRC64 && to.HasCRC64 {
+		compare("CRC64", strconv.FormatUint(from.CRC64, 10), strconv.FormatUint(to.CRC64, 10))
+	}
+	compare("Content-Type", from.ContentType, to.ContentType)
+	compare("Content-Encoding", from.ContentEncoding, to.ContentEncoding)
+	compare("Content-Disposition", from.ContentDisposition, to.ContentDisposition)
+	compare("Cache-Control", from.CacheControl, to.CacheControl)
+	compare("StorageClass", from.StorageClass, to.StorageClass)
+	keys := make([]string, 0, len(from.Meta)+len(to.Meta))
+	for k := range from.Meta {
+		keys = append(keys, k)
+	}
+	for k := range to.Meta {
+		if _, ok := from.Meta[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		compare("x-cos-meta-"+k, from.Meta[k], to.Meta[k])
+	}
+	return diff, nil
+}
+
+// PurgeNoncurrent 删除 prefix 下每个对象除当前版本和最新的 keepN 个非当前版本以外的所有版本（包括删除标记），
+// 返回合并后的批量删除结果。删除是不可恢复的。
+func (s *ObjectService) PurgeNoncurrent(ctx context.Context, prefix string, keepN int) (*ObjectDeleteMultiResult, error) {
+	if keepN < 0 {
+		return nil, fmt.Errorf("keepN must be non-negative, got %v", keepN)
+	}
+	res := &ObjectDeleteMultiResult{}
+	var pending []Object
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		v, _, err := s.DeleteMulti(ctx, &ObjectDeleteMultiOptions{Objects: pending})
+		if err != nil {
+			return err
+		}
+		res.DeletedObjects = append(res.DeletedObjects, v.DeletedObjects...)
+		res.Errors = append(res.Errors, v.Errors...)
+		pending = nil
+		return nil
+	}
+	err := s.client.walkObjectVersions(ctx, prefix, func(h *ObjectVersionHistory) error {
+		noncurrent := h.Noncurrent()
+		if len(noncurrent) <= keepN {
+			return nil
+		}
+		for _, v := range noncurrent[keepN:] {
+			pending = append(pending, Object{Key: v.Key, VersionId: v.VersionId})
+			// DeleteMulti 单次最多删除 1000 个
+			if len(pending) == 1000 {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
@@ -0,0 +1,200 @@
+package cos
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func testVersionsServer(t *testing.T, deleteMulti http.HandlerFunc) {
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == http.MethodPost && deleteMulti != nil {
+			deleteMulti(w, r)
+			return
+		}
+		testMethod(t, r, http.MethodGet)
+		if r.URL.Query().Get("key-marker") == "" {
+			fmt.Fprint(w, `<ListVersionsResult>
+	<EncodingType>url</EncodingType>
+	<IsTruncated>true</IsTruncated>
+	<NextKeyMarker>conf%2Fa.json</NextKeyMarker>
+	<NextVersionIdMarker>v2</NextVersionIdMarker>
+	<Version><Key>conf%2Fa.json</Key><VersionId>v2</VersionId><LastModified>2023-01-02T00:00:00.000Z</LastModified><Size>2</Size></Version>
+	<DeleteMarker><Key>conf%2Fa.json</Key><VersionId>d3</VersionId><IsLatest>true</IsLatest><LastModified>2023-01-03T00:00:00.000Z</LastModified></DeleteMarker>
+</ListVersionsResult>`)
+			return
+		}
+		fmt.Fprint(w, `<ListVersionsResult>
+	<EncodingType>url</EncodingType>
+	<Version><Key>conf%2Fa.json</Key><VersionId>v1</VersionId><LastModified>2023-01-01T00:00:00.000Z</LastModified><Size>1</Size></Version>
+	<Version><Key>conf%2Fa.jsonx</Key><VersionId>x1</VersionId><IsLatest>true</IsLatest><LastModified>2023-01-01T00:00:00.000Z</LastModified><Size>1</Size></Version>
+	<Version><Key>conf%2Fb.json</Key><VersionId>b2</VersionId><IsLatest>true</IsLatest><LastModified>2023-01-02T00:00:00.000Z</LastModified><Size>1</Size></Version>
+	<Version><Key>conf%2Fb.json</Key><VersionId>b1</VersionId><LastModified>2023-01-01T00:00:00.000Z</LastModified><Size>1</Size></Version>
+</ListVersionsResult>`)
+	})
+}
+
+func TestObjectService_ListVersions(t *testing.T) {
+	setup()
+	defer teardown()
+	testVersionsServer(t, nil)
+
+	h, err := client.Object.ListVersions(context.Background(), "conf/a.json")
+	if err != nil {
+		t.Fatalf("Object.ListVersions returned error: %v", err)
+	}
+	var ids []string
+	for _, v := range h.Versions {
+		ids = append(ids, v.VersionId)
+	}
+	if !reflect.DeepEqual(ids, []string{"d3", "v2", "v1"}) {
+		t.Errorf("Object.ListVersions returned versions %v", ids)
+	}
+	if !h.Deleted() || h.Latest().VersionId != "d3" || h.Find("v1") == nil || len(h.Noncurrent()) != 2 {
+		t.Errorf("Object.ListVersions returned history %+v", h)
+	}
+}
+
+func TestObjectService_ListVersions_Siblings(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var requests int
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		if q := r.URL.Query(); q.Get("prefix") != "logs" || q.Get("delimiter") != "/" {
+			t.Errorf("Object.ListVersions request query: %v", r.URL.RawQuery)
+		}
+		requests++
+		switch r.URL.Query().Get("key-marker") {
+		case "":
+			fmt.Fprint(w, `<ListVersionsResult>
+	<IsTruncated>true</IsTruncated>
+	<NextKeyMarker>logs</NextKeyMarker>
+	<NextVersionIdMarker>v2</NextVersionIdMarker>
+	<Version><Key>logs</Key><VersionId>v2</VersionId><IsLatest>true</IsLatest><LastModified>2023-01-02T00:00:00.000Z</LastModified></Version>
+</ListVersionsResult>`)
+		case "logs":
+			fmt.Fprint(w, `<ListVersionsResult>
+	<IsTruncated>true</IsTruncated>
+	<NextKeyMarker>logs.txt</NextKeyMarker>
+	<NextVersionIdMarker>x1</NextVersionIdMarker>
+	<Version><Key>logs</Key><VersionId>v1</VersionId><LastModified>2023-01-01T00:00:00.000Z</LastModified></Version>
+	<Version><Key>logs.txt</Key><VersionId>x1</VersionId><IsLatest>true</IsLatest><LastModified>2023-01-01T00:00:00.000Z</LastModified></Version>
+	<CommonPrefixes><Prefix>logs/</Prefix></CommonPrefixes>
+</ListVersionsResult>`)
+		default:
+			t.Errorf("Object.ListVersions listed sibling keys: %v", r.URL.RawQuery)
+			fmt.Fprint(w, `<ListVersionsResult></ListVersionsResult>`)
+		}
+	})
+
+	h, err := client.Object.ListVersions(context.Background(), "logs")
+	if err != nil {
+		t.Fatalf("Object.ListVersions returned error: %v", err)
+	}
+	if len(h.Versions) != 2 || h.Latest().VersionId != "v2" || requests != 2 {
+		t.Errorf("Object.ListVersions returned %+v after %v requests", h, requests)
+	}
+}
+
+func TestObjectService_RestoreVersion(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/conf/a.json", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodHead:
+			testFormValues(t, r, values{"versionId": "v1"})
+			w.Header().Set("Content-Length", "1")
+		case http.MethodPut:
+			want := fmt.Sprintf("%s/%s?versionId=v1", client.BaseURL.BucketURL.Host, encodeURIComponent("conf/a.json"))
+			if got := r.Header.Get("x-cos-copy-source"); got != want {
+				t.Errorf("x-cos-copy-source: %v, want %v", got, want)
+			}
+			fmt.Fprint(w, `<CopyObjectResult><ETag>"v1"</ETag></CopyObjectResult>`)
+		}
+	})
+	res, _, err := client.Object.RestoreVersion(context.Background(), "conf/a.json", "v1")
+	if err != nil {
+		t.Fatalf("Object.RestoreVersion returned error: %v", err)
+	}
+	if res.ETag != "\"v1\"" {
+		t.Errorf("Object.RestoreVersion returned ETag %v", res.ETag)
+	}
+	if _, _, err = client.Object.RestoreVersion(context.Background(), "conf/a.json", ""); err == nil {
+		t.Errorf("Object.RestoreVersion with empty version id expect error")
+	}
+}
+
+func TestObjectService_DiffVersions(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/conf/a.json", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodHead)
+		w.Header().Set("Content-Length", "10")
+		w.Header().Set("x-cos-hash-crc64ecma", "100")
+		if r.URL.Query().Get("versionId") == "v1" {
+			w.Header().Set("x-cos-meta-owner", "ops")
+			w.Header().Set("x-cos-hash-crc64ecma", "101")
+		}
+	})
+	diff, err := client.Object.DiffVersions(context.Background(), "conf/a.json", "v1", "")
+	if err != nil {
+		t.Fatalf("Object.DiffVersions returned error: %v", err)
+	}
+	want := []ObjectVersionChange{
+		{Field: "CRC64", From: "101", To: "100"},
+		{Field: "x-cos-meta-owner", From: "ops", To: ""},
+	}
+	if diff.SameContent || !reflect.DeepEqual(diff.Changes, want) {
+		t.Errorf("Object.DiffVersions returned %+v, want %+v", diff.Changes, want)
+	}
+}
+
+func TestObjectService_PurgeNoncurrent(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var deleted []string
+	testVersionsServer(t, func(w http.ResponseWriter, r *http.Request) {
+		v := &ObjectDeleteMultiOptions{}
+		xml.NewDecoder(r.Body).Decode(v)
+		for _, o := range v.Objects {
+			deleted = append(deleted, o.Key+"@"+o.VersionId)
+		}
+		fmt.Fprint(w, `<DeleteResult></DeleteResult>`)
+	})
+
+	if _, err := client.Object.PurgeNoncurrent(context.Background(), "conf/", -1); err == nil {
+		t.Errorf("Object.PurgeNoncurrent with negative keepN expect error")
+	}
+	_, err := client.Object.PurgeNoncurrent(context.Background(), "conf/", 1)
+	if err != nil {
+		t.Fatalf("Object.PurgeNoncurrent returned error: %v", err)
+	}
+	sort.Strings(deleted)
+	want := []string{"conf/a.json@v1"}
+	if !reflect.DeepEqual(deleted, want) {
+		t.Errorf("Object.PurgeNoncurrent deleted %v, want %v", deleted, want)
+	}
+
+	deleted = nil
+	if _, err = client.Object.PurgeNoncurrent(context.Background(), "conf/", 0); err != nil {
+		t.Fatalf("Object.PurgeNoncurrent returned error: %v", err)
+	}
+	sort.Strings(deleted)
+	want = []string{"conf/a.json@v1", "conf/a.json@v2", "conf/b.json@b1"}
+	if !reflect.DeepEqual(deleted, want) {
+		t.Errorf("Object.PurgeNoncurrent deleted %v, want %v", deleted, want)
+	}
+}
@@ -0,0 +1,391 @@
+package cos
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// 配置文件路径，未设置时使用 ~/.cos.yaml
+	EnvConfigFile = "COS_CONFIG_FILE"
+	// 使用的 profile，未设置时使用 default
+	EnvProfile = "COS_PROFILE"
+
+	defaultProfile = "default"
+)
+
+// ErrProfileNotFound 表示配置文件中不存在指定的 profile
+var ErrProfileNotFound = errors.New("profile not found")
+
+// Profile 是从配置文件和环境变量加载的客户端配置，零值表示使用默认值
+//
+// 配置文件支持两种格式：
+//
+// INI/TOML 风格，每个 section 是一个 profile，[profile dev] 与 [dev] 等价：
+//
+//	[default]
+//	bucket = examplebucket-1250000000
+//	region = ap-guangzhou
+//	secret_id = AKIDxxx
+//	secret_key = xxx
+//	retry_count = 5
+//	part_size = 16
+//
+// coscli 的 .cos.yaml，cos.base 中为密钥和协议，cos.buckets 中的每个存储桶是一个 profile，
+// 按 alias 或者 name 查找，default 不存在时使用第一个存储桶。暂不支持 coscli 加密保存的密钥。
+//
+// 配置项对应的环境变量为 COS_ 加上大写的配置项名称，例如 COS_SECRET_ID，环境变量优先于配置文件。
+type Profile struct {
+	Name string
+
+	Bucket string
+	Region string
+	// 服务域名，不包含存储桶名称，例如 cos.ap-guangzhou.myqcloud.com 或者 http://127.0.0.1:9000
+	Endpoint  string
+	Scheme    string
+	PathStyle bool
+
+	SecretID     string
+	SecretKey    string
+	SessionToken string
+	// 使用 CVM 角色获取临时密钥
+	CVMRoleName string
+
+	RetryCount     int
+	RetryInterval  time.Duration
+	AutoSwitchHost *bool
+
+	PartSize       int64
+	ThreadPoolSize int
+	EnableCRC      *bool
+}
+
+// profileKeys 是支持的配置项，key 为规范名称，value 为别名
+var profileKeys = map[string][]string{
+	"bucket":           nil,
+	"region":           nil,
+	"endpoint":         nil,
+	"scheme":           {"protocol"},
+	"path_style":       nil,
+	"secret_id":        {"secretid"},
+	"secret_key":       {"secretkey"},
+	"session_token":    {"sessiontoken"},
+	"cvm_role_name":    {"cvmrolename"},
+	"retry_count":      nil,
+	"retry_interval":   nil,
+	"auto_switch_host": nil,
+	"part_size":        nil,
+	"thread_pool_size": nil,
+	"enable_crc":       nil,
+}
+
+func canonicalProfileKey(key string) string {
+	key = strings.Replace(strings.ToLower(strings.TrimSpace(key)), "-", "_", -1)
+	for k, aliases := range profileKeys {
+		if key == k {
+			return k
+		}
+		for _, alias := range aliases {
+			if key == alias {
+				return k
+			}
+		}
+	}
+	return ""
+}
+
+// set 设置一个配置项，不支持的配置项会被忽略，数值和布尔类型的配置项为空时不生效
+func (p *Profile) set(key, value string) (err error) {
+	parseBool := func() *bool {
+		var b bool
+		b, err = strconv.ParseBool(value)
+		return &b
+	}
+	key = canonicalProfileKey(key)
+	switch key {
+	case "bucket", "region", "endpoint", "scheme", "secret_id", "secret_key", "session_token", "cvm_role_name":
+	default:
+		if value == "" {
+			return nil
+		}
+	}
+	switch key {
+	case "bucket":
+		p.Bucket = value
+	case "region":
+		p.Region = value
+	case "endpoint":
+		p.Endpoint = value
+	case "scheme":
+		p.Scheme = value
+	case "path_style":
+		p.PathStyle = *parseBool()
+	case "secret_id":
+		p.SecretID = value
+	case "secret_key":
+		p.SecretKey = value
+	case "session_token":
+		p.SessionToken = value
+	case "cvm_role_name":
+		p.CVMRoleName = value
+	case "retry_count":
+		p.RetryCount, err = strconv.Atoi(value)
+	case "retry_interval":
+		p.RetryInterval, err = time.ParseDuration(value)
+	case "auto_switch_host":
+		p.AutoSwitchHost = parseBool()
+	case "part_size":
+		p.PartSize, err = strconv.ParseInt(value, 10, 64)
+	case "thread_pool_size":
+		p.ThreadPoolSize, err = strconv.Atoi(value)
+	case "enable_crc":
+		p.EnableCRC = parseBool()
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %v[%v]: %v", key, value, err)
+	}
+	return nil
+}
+
+// LoadProfile 从配置文件和环境变量加载 profile。
+// name 为空时使用环境变量 COS_PROFILE，未设置时使用 default；
+// 配置文件不存在时只使用环境变量
+func LoadProfile(name string) (*Profile, error) {
+	if name == "" {
+		name = os.Getenv(EnvProfile)
+	}
+	if name == "" {
+		name = defaultProfile
+	}
+	path := os.Getenv(EnvConfigFile)
+	if path == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, ".cos.yaml")
+		}
+	}
+	p, err := LoadProfileFile(path, name)
+	// 配置文件不存在，或者其中没有 default 时只使用环境变量
+	if os.IsNotExist(err) || (errors.Is(err, ErrProfileNotFound) && name == defaultProfile) {
+		p, err = &Profile{Name: name}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for key := range profileKeys {
+		if v, ok := os.LookupEnv("COS_" + strings.ToUpper(key)); ok {
+			if err = p.set(key, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return p, nil
+}
+
+// LoadProfileFile 从配置文件 path 中读取名为 name 的 profile，不读取环境变量
+func LoadProfileFile(path, name string) (*Profile, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	if name == "" {
+		name = defaultProfile
+	}
+	profiles, err := parseProfiles(fd)
+	if err != nil {
+		return nil, fmt.Errorf("parse %v failed: %v", path, err)
+	}
+	for _, p := range profiles {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("%v in %v: %w", name, path, ErrProfileNotFound)
+}
+
+// parseProfiles 解析配置文件，coscli 格式的存储桶同时按 alias 和 name 生成 profile
+func parseProfiles(r io.Reader) ([]*Profile, error) {
+	type entry struct {
+		names  []string
+		values [][2]string
+	}
+	var (
+		entries []*entry
+		base    [][2]string
+		current *entry
+		coscli  bool
+		inBase  bool
+		scanner = bufio.NewScanner(r)
+		lineNum int
+	)
+	for scanner.Scan() {
+		lineNum++
+		raw := strings.TrimRight(scanner.Text(), " \t\r")
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		// 顶层的 cos: 表示 coscli 的配置文件
+		if !coscli && len(entries) == 0 && line == "cos:" && raw == line {
+			coscli = true
+			continue
+		}
+		if coscli {
+			switch line {
+			case "base:":
+				inBase, current = true, nil
+				continue
+			case "buckets:":
+				inBase, current = false, nil
+				continue
+			}
+			if strings.HasPrefix(line, "- ") {
+				inBase = false
+				current = &entry{}
+				entries = append(entries, current)
+				line = strings.TrimSpace(line[2:])
+			}
+		} else if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			name = strings.TrimSpace(strings.TrimPrefix(name, "profile "))
+			current = &entry{names: []string{name}}
+			entries = append(entries, current)
+			continue
+		}
+		k, v, ok := splitProfileLine(line)
+		if !ok {
+			return nil, fmt.Errorf("line %v: invalid format %q", lineNum, line)
+		}
+		switch {
+		case coscli && inBase:
+			base = append(base, [2]string{k, v})
+		case current != nil:
+			current.values = append(current.values, [2]string{k, v})
+			if coscli && (k == "alias" || k == "name") && v != "" {
+				current.names = append(current.names, v)
+			}
+		case !coscli:
+			return nil, fmt.Errorf("line %v: key %v outside of profile", lineNum, k)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var profiles []*Profile
+	for _, e := range entries {
+		for _, name := range e.names {
+			p := &Profile{Name: name}
+			for _, kv := range append(append([][2]string{}, base...), e.values...) {
+				if err := p.setFileKey(kv[0], kv[1]); err != nil {
+					return nil, err
+				}
+			}
+			profiles = append(profiles, p)
+		}
+	}
+	// coscli 的配置文件中没有名为 default 的存储桶时使用第一个存储桶
+	if coscli && len(profiles) > 0 {
+		p := *profiles[0]
+		p.Name = defaultProfile
+		profiles = append(profiles, &p)
+	}
+	return profiles, nil
+}
+
+// setFileKey 设置配置文件中的配置项，兼容 coscli 的配置项
+func (p *Profile) setFileKey(key, value string) error {
+	switch strings.ToLower(key) {
+	case "name":
+		p.Bucket = value
+		return nil
+	case "closeautoswitchhost":
+		close, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid %v[%v]: %v", key, value, err)
+		}
+		p.AutoSwitchHost = Bool(!close)
+		return nil
+	case "mode":
+		// coscli 的 CvmRole 模式通过 cvmrolename 生效
+		return nil
+	}
+	return p.set(key, value)
+}
+
+// splitProfileLine 解析 key = value 或者 key: value，去掉引号和行尾注释
+func splitProfileLine(line string) (string, string, bool) {
+	idx := strings.IndexAny(line, "=:")
+	if idx <= 0 {
+		return "", "", false
+	}
+	key := strings.TrimSpace(line[:idx])
+	value := strings.TrimSpace(line[idx+1:])
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+		if end := strings.IndexByte(value[1:], value[0]); end >= 0 {
+			return key, value[1 : end+1], true
+		}
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return key, value, true
+}
+
+// Options 返回 New 使用的 Option
+func (p *Profile) Options() []Option {
+	var opts []Option
+	if p.Bucket != "" {
+		opts = append(opts, WithBucket(p.Bucket, p.Region))
+	}
+	if p.Endpoint != "" {
+		opts = append(opts, WithEndpoint(p.Endpoint))
+	}
+	if p.PathStyle {
+		opts = append(opts, WithPathStyle())
+	}
+	if p.Scheme != "" {
+		opts = append(opts, WithScheme(p.Scheme))
+	}
+	if p.CVMRoleName != "" {
+		opts = append(opts, WithHTTPClient(&http.Client{
+			Transport: &CVMCredentialTransport{RoleName: p.CVMRoleName},
+		}))
+	} else if p.SecretID != "" || p.SecretKey != "" {
+		opts = append(opts, WithCredentials(p.SecretID, p.SecretKey, p.SessionToken))
+	}
+	if p.RetryCount != 0 || p.RetryInterval != 0 || p.AutoSwitchHost != nil {
+		retry := RetryOptions{Count: 3, Interval: p.RetryInterval}
+		if p.RetryCount != 0 {
+			retry.Count = p.RetryCount
+		}
+		if p.AutoSwitchHost != nil {
+			retry.AutoSwitchHost = *p.AutoSwitchHost
+		}
+		opts = append(opts, WithRetryPolicy(retry))
+	}
+	if p.PartSize != 0 || p.ThreadPoolSize != 0 {
+		opts = append(opts, WithTransferDefaults(p.PartSize, p.ThreadPoolSize))
+	}
+	if p.EnableCRC != nil {
+		opts = append(opts, WithCRC(*p.EnableCRC))
+	}
+	return opts
+}
+
+// NewClientFromProfile 使用 LoadProfile 加载的配置创建 Client，opts 在 profile 之后生效
+func NewClientFromProfile(name string, opts ...Option) (*Client, error) {
+	p, err := LoadProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	return New(append(p.Options(), opts...)...)
+}
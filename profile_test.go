@@ -0,0 +1,191 @@
+package cos
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testProfileINI = `
+# 本地开发环境
+[default]
+bucket = examplebucket-1250000000
+region = ap-guangzhou
+secret_id = "AKIDdefault"
+secret_key = default-key # 注释
+
+[profile dev]
+bucket: devbucket-1250000000
+region: ap-shanghai
+endpoint = http://127.0.0.1:9000
+path-style = true
+retry_count = 5
+retry_interval = 100ms
+auto_switch_host = false
+part_size = 16
+thread_pool_size = 4
+enable_crc = false
+`
+
+const testProfileCOSCLI = `cos:
+  base:
+    secretid: AKIDcoscli
+    secretkey: coscli-key
+    sessiontoken: ""
+    protocol: https
+    mode: SecretKey
+    cvmrolename: ""
+    closeautoswitchhost: "true"
+  buckets:
+  - name: examplebucket-1250000000
+    alias: bucket1
+    region: ap-guangzhou
+    endpoint: cos.ap-guangzhou.myqcloud.com
+    ofs: false
+  - name: other-1250000000
+    alias: bucket2
+    region: ap-beijing
+    endpoint: ""
+    ofs: false
+`
+
+func writeTestProfile(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "cosprofile")
+	if err != nil {
+		t.Fatalf("TempDir returned error: %v", err)
+	}
+	path := filepath.Join(dir, "config")
+	if err = ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("WriteFile returned error: %v", err)
+	}
+	return path
+}
+
+func TestLoadProfileFile(t *testing.T) {
+	path := writeTestProfile(t, testProfileINI)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	p, err := LoadProfileFile(path, "")
+	if err != nil {
+		t.Fatalf("LoadProfileFile returned error: %v", err)
+	}
+	want := &Profile{
+		Name:      "default",
+		Bucket:    "examplebucket-1250000000",
+		Region:    "ap-guangzhou",
+		SecretID:  "AKIDdefault",
+		SecretKey: "default-key",
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("LoadProfileFile returned %+v, want %+v", p, want)
+	}
+
+	p, err = LoadProfileFile(path, "dev")
+	if err != nil {
+		t.Fatalf("LoadProfileFile returned error: %v", err)
+	}
+	want = &Profile{
+		Name:           "dev",
+		Bucket:         "devbucket-1250000000",
+		Region:         "ap-shanghai",
+		Endpoint:       "http://127.0.0.1:9000",
+		PathStyle:      true,
+		RetryCount:     5,
+		RetryInterval:  100 * time.Millisecond,
+		AutoSwitchHost: Bool(false),
+		PartSize:       16,
+		ThreadPoolSize: 4,
+		EnableCRC:      Bool(false),
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("LoadProfileFile returned %+v, want %+v", p, want)
+	}
+
+	if _, err = LoadProfileFile(path, "prod"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("LoadProfileFile with unknown profile returned error: %v", err)
+	}
+	bad := writeTestProfile(t, "[default]\nretry_count = many\n")
+	defer os.RemoveAll(filepath.Dir(bad))
+	if _, err = LoadProfileFile(bad, ""); err == nil || !strings.Contains(err.Error(), "retry_count") {
+		t.Errorf("LoadProfileFile with invalid value returned error: %v", err)
+	}
+}
+
+func TestLoadProfileFile_COSCLI(t *testing.T) {
+	path := writeTestProfile(t, testProfileCOSCLI)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	for _, name := range []string{"", "bucket1", "examplebucket-1250000000"} {
+		p, err := LoadProfileFile(path, name)
+		if err != nil {
+			t.Fatalf("LoadProfileFile(%q) returned error: %v", name, err)
+		}
+		if p.Bucket != "examplebucket-1250000000" || p.Region != "ap-guangzhou" || p.Endpoint != "cos.ap-guangzhou.myqcloud.com" ||
+			p.SecretID != "AKIDcoscli" || p.SecretKey != "coscli-key" || p.Scheme != "https" || p.AutoSwitchHost == nil || *p.AutoSwitchHost {
+			t.Errorf("LoadProfileFile(%q) returned %+v", name, p)
+		}
+	}
+	p, err := LoadProfileFile(path, "bucket2")
+	if err != nil || p.Bucket != "other-1250000000" || p.Region != "ap-beijing" || p.Endpoint != "" || p.SecretID != "AKIDcoscli" {
+		t.Errorf("LoadProfileFile(bucket2) returned %+v, error: %v", p, err)
+	}
+}
+
+func TestNewClientFromProfile(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if !strings.Contains(r.Header.Get("Authorization"), "q-ak=AKIDenv") {
+			t.Errorf("Authorization: %v", r.Header.Get("Authorization"))
+		}
+	}))
+	defer server.Close()
+
+	path := writeTestProfile(t, testProfileINI)
+	defer os.RemoveAll(filepath.Dir(path))
+	env := map[string]string{
+		EnvConfigFile:    path,
+		EnvProfile:       "dev",
+		"COS_ENDPOINT":   server.URL,
+		"COS_SECRET_ID":  "AKIDenv",
+		"COS_SECRET_KEY": "env-key",
+	}
+	for k, v := range env {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+
+	c, err := NewClientFromProfile("", WithUserAgentSuffix("app"))
+	if err != nil {
+		t.Fatalf("NewClientFromProfile returned error: %v", err)
+	}
+	if c.Conf.EnableCRC || c.Conf.RetryOpt.Count != 5 || c.Conf.RetryOpt.AutoSwitchHost || c.Conf.PartSize != 16 || c.Conf.ThreadPoolSize != 4 {
+		t.Errorf("Conf: %+v", c.Conf)
+	}
+	if !strings.HasSuffix(c.UserAgent, " app") {
+		t.Errorf("UserAgent: %v", c.UserAgent)
+	}
+	if _, err = c.Object.Head(context.Background(), "a", nil); err != nil {
+		t.Fatalf("Object.Head returned error: %v", err)
+	}
+	if len(paths) != 1 || paths[0] != "/devbucket-1250000000/a" {
+		t.Errorf("request paths: %v", paths)
+	}
+
+	if _, err = NewClientFromProfile("prod"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("NewClientFromProfile with unknown profile returned error: %v", err)
+	}
+	os.Setenv("COS_REGION", "")
+	defer os.Unsetenv("COS_REGION")
+	if _, err = NewClientFromProfile("dev"); err == nil {
+		t.Errorf("NewClientFromProfile without region expect error")
+	}
+}
@@ -1,6 +1,7 @@
 package cos
 
 import (
+	"context"
 	"fmt"
 	"hash"
 	"io"
@@ -58,6 +59,9 @@ type teeReader struct {
 	totalBytes      int64
 	listener        ProgressListener
 	disableCheckSum bool
+	// 上传带宽限速，参见 RateLimit.Upload
+	limiter *RateLimiter
+	ctx     context.Context
 }
 
 func (r *teeReader) Read(p []byte) (int, error) {
@@ -66,7 +70,12 @@ func (r *teeReader) Read(p []byte) (int, error) {
 		progressCallback(r.listener, event)
 	}
 
-	n, err := r.reader.Read(p)
+	n, err := r.reader.Read(r.limiter.chunk(p))
+	if n > 0 && r.limiter != nil {
+		if werr := r.limiter.WaitN(r.ctx, n); werr != nil && (err == nil || err == io.EOF) {
+			err = werr
+		}
+	}
 	if err != nil && err != io.EOF {
 		event := newProgressEvent(ProgressFailedEvent, 0, r.consumedBytes, r.totalBytes, err)
 		progressCallback(r.listener, event)
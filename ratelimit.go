@@ -0,0 +1,172 @@
+package cos
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiter 是令牌桶限速器，并发安全，可以在多个 Client 之间共享以限制进程级别的总量
+type RateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter 创建每秒产生 rate 个令牌、最多累积 burst 个令牌的限速器，burst <= 0 时为一秒的令牌数。
+// 用于带宽限制时令牌的单位为字节
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst <= 0 {
+		burst = int(math.Ceil(rate))
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// WaitN 取出 n 个令牌，令牌不足时等待，ctx 结束时归还令牌并返回错误。
+// n 可以大于 burst，超出的部分按速率预支，后续的调用者顺延等待
+func (l *RateLimiter) WaitN(ctx context.Context, n int) error {
+	if l == nil || n <= 0 || l.rate <= 0 {
+		return nil
+	}
+	d := l.reserve(float64(n))
+	if d <= 0 {
+		return nil
+	}
+	if err := sleepWithContext(ctx, d); err != nil {
+		l.reserve(-float64(n))
+		return err
+	}
+	return nil
+}
+
+// reserve 取出 n 个令牌，返回令牌补足前需要等待的时间
+func (l *RateLimiter) reserve(n float64) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens -= n
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// chunk 限制单次读取的长度，避免一次读取超过 burst 造成突发流量
+func (l *RateLimiter) chunk(p []byte) []byte {
+	if l != nil && l.rate > 0 && float64(len(p)) > l.burst {
+		return p[:int(l.burst)]
+	}
+	return p
+}
+
+// OperationClass 是按请求方法划分的操作类别，用于 RateLimit.Requests
+type OperationClass string
+
+const (
+	// 所有请求
+	OperationAll OperationClass = "all"
+	// GET、HEAD 请求
+	OperationRead OperationClass = "read"
+	// PUT、POST 请求
+	OperationWrite OperationClass = "write"
+	// DELETE 请求
+	OperationDelete OperationClass = "delete"
+)
+
+func operationClass(method string) OperationClass {
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return OperationRead
+	case http.MethodDelete:
+		return OperationDelete
+	}
+	return OperationWrite
+}
+
+// RateLimit 是 Client 级别的限速配置，由所有 goroutine 和传输共享。与 XCosTrafficLimit 不同，
+// 限速在本地进行，对并发的 Upload、Download 等调用整体生效
+type RateLimit struct {
+	// 上传数据的总带宽，单位 Byte/s，作用于 Put、Append、UploadPart 等请求的 body
+	Upload *RateLimiter
+	// 下载数据的总带宽，单位 Byte/s，作用于所有响应的 body
+	Download *RateLimiter
+	// 按操作类别限制每秒的请求数，OperationAll 作用于所有请求，重试和对冲的请求同样计入
+	Requests map[OperationClass]*RateLimiter
+}
+
+// NewBandwidthLimit 创建只限制带宽的 RateLimit，单位 Byte/s，为 0 时不限制
+func NewBandwidthLimit(upload, download int64) *RateLimit {
+	l := &RateLimit{}
+	if upload > 0 {
+		l.Upload = NewRateLimiter(float64(upload), 0)
+	}
+	if download > 0 {
+		l.Download = NewRateLimiter(float64(download), 0)
+	}
+	return l
+}
+
+// waitRequest 发送请求前等待请求数的令牌
+func (l *RateLimit) waitRequest(ctx context.Context, method string) error {
+	if l == nil || len(l.Requests) == 0 {
+		return nil
+	}
+	if err := l.Requests[OperationAll].WaitN(ctx, 1); err != nil {
+		return err
+	}
+	return l.Requests[operationClass(method)].WaitN(ctx, 1)
+}
+
+// limitUpload 对通过 teeReader 上传的 body 限速
+func (l *RateLimit) limitUpload(ctx context.Context, body interface{}) {
+	if l == nil || l.Upload == nil {
+		return
+	}
+	if r, ok := body.(*teeReader); ok {
+		r.limiter = l.Upload
+		r.ctx = ctx
+	}
+}
+
+// limitDownload 对响应 body 限速
+func (l *RateLimit) limitDownload(ctx context.Context, resp *http.Response) {
+	if l == nil || l.Download == nil || resp.Body == nil {
+		return
+	}
+	resp.Body = &rateLimitedReader{ReadCloser: resp.Body, limiter: l.Download, ctx: ctx}
+}
+
+type rateLimitedReader struct {
+	io.ReadCloser
+	limiter *RateLimiter
+	ctx     context.Context
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(r.limiter.chunk(p))
+	if n > 0 {
+		if werr := r.limiter.WaitN(r.ctx, n); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,148 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_WaitN(t *testing.T) {
+	l := NewRateLimiter(1000, 100)
+	start := time.Now()
+	if err := l.WaitN(context.Background(), 100); err != nil || time.Since(start) > 20*time.Millisecond {
+		t.Errorf("WaitN within burst returned %v after %v", err, time.Since(start))
+	}
+	if err := l.WaitN(context.Background(), 100); err != nil || time.Since(start) < 80*time.Millisecond {
+		t.Errorf("WaitN returned %v after %v, want about 100ms", err, time.Since(start))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.WaitN(ctx, 1000); err != context.DeadlineExceeded {
+		t.Errorf("WaitN returned %v, want DeadlineExceeded", err)
+	}
+	// 取消后归还令牌
+	if d := l.reserve(0); d > 20*time.Millisecond {
+		t.Errorf("reserve after cancel returned %v", d)
+	}
+	if err := (*RateLimiter)(nil).WaitN(context.Background(), 1); err != nil {
+		t.Errorf("nil RateLimiter returned %v", err)
+	}
+}
+
+func TestObjectService_PutBandwidthLimit(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var mu sync.Mutex
+	var received int
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		received += len(b)
+		mu.Unlock()
+	})
+	client.Conf.EnableCRC = false
+	client.Conf.RateLimit = &RateLimit{Upload: NewRateLimiter(50*1024, 5*1024)}
+
+	// 两个并发的上传共享 50KB/s 的带宽，除去 5KB 的 burst 至少需要 300ms
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, name := range []string{"a", "b"} {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			if _, err := client.Object.Put(context.Background(), name, bytes.NewReader(make([]byte, 10*1024)), nil); err != nil {
+				t.Errorf("Object.Put returned error: %v", err)
+			}
+		}(name)
+	}
+	wg.Wait()
+	if d := time.Since(start); d < 250*time.Millisecond || received != 20*1024 {
+		t.Errorf("Object.Put sent %v bytes in %v", received, d)
+	}
+}
+
+func TestObjectService_GetBandwidthLimit(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(make([]byte, 20*1024))
+	})
+	client.Conf.RateLimit = &RateLimit{Download: NewRateLimiter(100*1024, 10*1024)}
+
+	start := time.Now()
+	resp, err := client.Object.Get(context.Background(), "test", nil)
+	if err != nil {
+		t.Fatalf("Object.Get returned error: %v", err)
+	}
+	b, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if d := time.Since(start); len(b) != 20*1024 || d < 80*time.Millisecond {
+		t.Errorf("Object.Get read %v bytes in %v", len(b), d)
+	}
+}
+
+func TestClient_RequestRateLimit(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {})
+	client.Conf.RateLimit = &RateLimit{Requests: map[OperationClass]*RateLimiter{
+		OperationRead: NewRateLimiter(20, 1),
+	}}
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := client.Object.Head(context.Background(), "test", nil); err != nil {
+			t.Fatalf("Object.Head returned error: %v", err)
+		}
+	}
+	if d := time.Since(start); d < 180*time.Millisecond {
+		t.Errorf("5 Object.Head took %v, want at least 200ms", d)
+	}
+	// 其他类别的请求不受限制
+	start = time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := client.Object.Delete(context.Background(), "test"); err != nil {
+			t.Fatalf("Object.Delete returned error: %v", err)
+		}
+	}
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Errorf("5 Object.Delete took %v", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.Object.Head(ctx, "test", nil); err == nil || !strings.Contains(err.Error(), "canceled") {
+		t.Errorf("Object.Head with canceled context returned error: %v", err)
+	}
+}
+
+func TestNew_WithBandwidthLimit(t *testing.T) {
+	reqs := map[OperationClass]*RateLimiter{OperationAll: NewRateLimiter(10, 0)}
+	c, err := New(WithRateLimit(&RateLimit{Requests: reqs}), WithBandwidthLimit(1024, 0))
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if l := c.Conf.RateLimit; l == nil || l.Upload == nil || l.Download != nil || l.Requests[OperationAll] == nil {
+		t.Errorf("RateLimit: %+v", l)
+	}
+	// 与选项的顺序无关
+	c, err = New(WithBandwidthLimit(1024, 0), WithRateLimit(&RateLimit{Requests: reqs}))
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if l := c.Conf.RateLimit; l == nil || l.Upload == nil || l.Download != nil || l.Requests[OperationAll] == nil {
+		t.Errorf("RateLimit: %+v", l)
+	}
+	if _, err = New(WithBandwidthLimit(-1, 0)); err == nil {
+		t.Errorf("WithBandwidthLimit with negative value expect error")
+	}
+}
@@ -0,0 +1,260 @@
+package cos
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	defaultTransferProgressInterval = 500 * time.Millisecond
+	defaultTransferProgressWindow   = 5 * time.Second
+)
+
+// TransferProgressEvent 是整个传输（Upload/Download/MultiCopy）的聚合进度
+type TransferProgressEvent struct {
+	EventType ProgressEventType
+	// 所有分块、所有 worker 已传输的字节数，分块重试时会扣除该分块此前已计入的字节
+	ConsumedBytes int64
+	TotalBytes    int64
+	// 已完成的分块数
+	CompletedParts int
+	TotalParts     int
+	// 滑动窗口内的平均速率，单位 Bytes/s
+	Throughput float64
+	// 预计剩余时间，速率未知时为 -1
+	ETA time.Duration
+	// 从传输开始到当前的耗时
+	Elapsed time.Duration
+	Err     error
+}
+
+// TransferProgressListener 接收聚合后的传输进度
+type TransferProgressListener interface {
+	TransferProgressChangedCallback(event *TransferProgressEvent)
+}
+
+// TransferProgressOptions 是传输进度的选项
+type TransferProgressOptions struct {
+	Listener TransferProgressListener
+	// ProgressDataEvent 的最小回调间隔，默认 500ms，小于 0 时不限制。
+	// Started/Completed/Failed 事件不受限制。
+	Interval time.Duration
+	// 计算平均速率的滑动窗口大小，默认 5s
+	Window time.Duration
+}
+
+type transferSample struct {
+	t        time.Time
+	consumed int64
+}
+
+// TransferTracker 聚合多个分块、多个 worker 的传输进度
+type TransferTracker struct {
+	mu         sync.Mutex
+	listener   TransferProgressListener
+	interval   time.Duration
+	window     time.Duration
+	totalBytes int64
+	totalParts int
+	consumed   int64
+	parts      map[int]int64
+	completed  map[int]bool
+	samples    []transferSample
+	start      time.Time
+	lastEmit   time.Time
+	finished   bool
+	now        func() time.Time
+}
+
+// NewTransferTracker 创建 TransferTracker，opt 为 nil 或者没有 Listener 时返回 nil，
+// nil 的 TransferTracker 可以安全调用所有方法。
+func NewTransferTracker(totalBytes int64, totalParts int, opt *TransferProgressOptions) *TransferTracker {
+	if opt == nil || opt.Listener == nil {
+		return nil
+	}
+	t := &TransferTracker{
+		listener:   opt.Listener,
+		interval:   opt.Interval,
+		window:     opt.Window,
+		totalBytes: totalBytes,
+		totalParts: totalParts,
+		parts:      make(map[int]int64),
+		completed:  make(map[int]bool),
+		now:        time.Now,
+	}
+	if t.interval == 0 {
+		t.interval = defaultTransferProgressInterval
+	}
+	if t.window <= 0 {
+		t.window = defaultTransferProgressWindow
+	}
+	return t
+}
+
+// Start 开始计时并发送 ProgressStartedEvent
+func (t *TransferTracker) Start() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.start = t.now()
+	t.lastEmit = t.start
+	t.samples = append(t.samples[:0], transferSample{t.start, t.consumed})
+	event := t.eventLocked(ProgressStartedEvent, nil)
+	t.mu.Unlock()
+	t.listener.TransferProgressChangedCallback(event)
+}
+
+// PartListener 返回分块 partNumber 的 ProgressListener，用于传入 UploadPart/Get 的 Listener。
+// 每次请求的 teeReader 都从 0 开始计数，因此分块重试时会自动扣除上一次请求已计入的字节。
+func (t *TransferTracker) PartListener(partNumber int) ProgressListener {
+	return t.partListener(partNumber, nil)
+}
+
+func (t *TransferTracker) partListener(partNumber int, next ProgressListener) ProgressListener {
+	if t == nil {
+		return next
+	}
+	return &transferPartListener{tracker: t, part: partNumber, next: next}
+}
+
+type transferPartListener struct {
+	tracker *TransferTracker
+	part    int
+	next    ProgressListener
+}
+
+func (l *transferPartListener) ProgressChangedCallback(event *ProgressEvent) {
+	switch event.EventType {
+	case ProgressStartedEvent, ProgressDataEvent, ProgressCompletedEvent:
+		l.tracker.setPartBytes(l.part, event.ConsumedBytes)
+	}
+	progressCallback(l.next, event)
+}
+
+func (t *TransferTracker) setPartBytes(part int, n int64) {
+	t.mu.Lock()
+	if t.completed[part] {
+		t.mu.Unlock()
+		return
+	}
+	t.consumed += n - t.parts[part]
+	t.parts[part] = n
+	event := t.dataEventLocked(false)
+	t.mu.Unlock()
+	if event != nil {
+		t.listener.TransferProgressChangedCallback(event)
+	}
+}
+
+// CompletePart 标记分块完成，该分块计入的字节数修正为 size
+func (t *TransferTracker) CompletePart(partNumber int, size int64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	if t.completed[partNumber] {
+		t.mu.Unlock()
+		return
+	}
+	t.consumed += size - t.parts[partNumber]
+	t.parts[partNumber] = size
+	t.completed[partNumber] = true
+	event := t.dataEventLocked(len(t.completed) == t.totalParts)
+	t.mu.Unlock()
+	if event != nil {
+		t.listener.TransferProgressChangedCallback(event)
+	}
+}
+
+// Complete 发送 ProgressCompletedEvent
+func (t *TransferTracker) Complete() {
+	t.finish(ProgressCompletedEvent, nil)
+}
+
+// Fail 发送 ProgressFailedEvent
+func (t *TransferTracker) Fail(err error) {
+	t.finish(ProgressFailedEvent, err)
+}
+
+func (t *TransferTracker) finish(eventType ProgressEventType, err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	if t.finished {
+		t.mu.Unlock()
+		return
+	}
+	t.finished = true
+	t.addSampleLocked()
+	event := t.eventLocked(eventType, err)
+	t.mu.Unlock()
+	t.listener.TransferProgressChangedCallback(event)
+}
+
+// Progress 返回当前的聚合进度
+func (t *TransferTracker) Progress() *TransferProgressEvent {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.eventLocked(ProgressDataEvent, nil)
+}
+
+func (t *TransferTracker) dataEventLocked(force bool) *TransferProgressEvent {
+	if t.finished {
+		return nil
+	}
+	now := t.addSampleLocked()
+	if !force && t.interval > 0 && now.Sub(t.lastEmit) < t.interval {
+		return nil
+	}
+	t.lastEmit = now
+	return t.eventLocked(ProgressDataEvent, nil)
+}
+
+func (t *TransferTracker) addSampleLocked() time.Time {
+	now := t.now()
+	if t.start.IsZero() {
+		t.start = now
+	}
+	t.samples = append(t.samples, transferSample{now, t.consumed})
+	// 保留窗口内的样本，以及窗口外最近的一个样本作为起点
+	i := 0
+	for i+1 < len(t.samples) && now.Sub(t.samples[i+1].t) >= t.window {
+		i++
+	}
+	if i > 0 {
+		t.samples = append(t.samples[:0], t.samples[i:]...)
+	}
+	return now
+}
+
+func (t *TransferTracker) eventLocked(eventType ProgressEventType, err error) *TransferProgressEvent {
+	event := &TransferProgressEvent{
+		EventType:      eventType,
+		ConsumedBytes:  t.consumed,
+		TotalBytes:     t.totalBytes,
+		CompletedParts: len(t.completed),
+		TotalParts:     t.totalParts,
+		ETA:            -1,
+		Err:            err,
+	}
+	if len(t.samples) == 0 {
+		return event
+	}
+	last := t.samples[len(t.samples)-1]
+	event.Elapsed = last.t.Sub(t.start)
+	first := t.samples[0]
+	if d := last.t.Sub(first.t); d > 0 && last.consumed > first.consumed {
+		event.Throughput = float64(last.consumed-first.consumed) / d.Seconds()
+	}
+	if eventType == ProgressCompletedEvent {
+		event.ETA = 0
+	} else if event.Throughput > 0 && t.totalBytes >= t.consumed {
+		event.ETA = time.Duration(float64(t.totalBytes-t.consumed) / event.Throughput * float64(time.Second))
+	}
+	return event
+}
@@ -0,0 +1,296 @@
+package cos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc64"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testTransferListener struct {
+	mu     sync.Mutex
+	events []TransferProgressEvent
+}
+
+func (l *testTransferListener) TransferProgressChangedCallback(event *TransferProgressEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, *event)
+}
+
+func (l *testTransferListener) check(t *testing.T, totalBytes int64, totalParts int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.events) < 2 {
+		t.Fatalf("got %d transfer events", len(l.events))
+	}
+	if l.events[0].EventType != ProgressStartedEvent {
+		t.Errorf("first event is %v", l.events[0].EventType)
+	}
+	for _, e := range l.events {
+		if e.ConsumedBytes < 0 || e.ConsumedBytes > totalBytes || e.TotalBytes != totalBytes || e.TotalParts != totalParts {
+			t.Errorf("unexpected event: %+v", e)
+		}
+	}
+	last := l.events[len(l.events)-1]
+	if last.EventType != ProgressCompletedEvent || last.ConsumedBytes != totalBytes || last.CompletedParts != totalParts || last.ETA != 0 {
+		t.Errorf("unexpected last event: %+v", last)
+	}
+}
+
+func TestTransferTracker(t *testing.T) {
+	if NewTransferTracker(100, 1, nil) != nil || NewTransferTracker(100, 1, &TransferProgressOptions{}) != nil {
+		t.Fatalf("NewTransferTracker expect nil")
+	}
+	// nil tracker 可以安全调用
+	var nt *TransferTracker
+	nt.Start()
+	nt.CompletePart(1, 1)
+	nt.Fail(errors.New("err"))
+	if nt.PartListener(1) != nil || nt.Progress() != nil {
+		t.Errorf("nil TransferTracker expect nil listener")
+	}
+
+	l := &testTransferListener{}
+	tracker := NewTransferTracker(300, 3, &TransferProgressOptions{
+		Listener: l,
+		Interval: time.Second,
+		Window:   4 * time.Second,
+	})
+	now := time.Unix(1000, 0)
+	tracker.now = func() time.Time { return now }
+	tracker.Start()
+
+	p1, p2 := tracker.PartListener(1), tracker.PartListener(2)
+	// 距离 Start 不足一个间隔
+	p1.ProgressChangedCallback(newProgressEvent(ProgressStartedEvent, 0, 0, 100))
+	now = now.Add(time.Second)
+	p1.ProgressChangedCallback(newProgressEvent(ProgressDataEvent, 50, 50, 100))
+	// 间隔内的事件被丢弃
+	p2.ProgressChangedCallback(newProgressEvent(ProgressDataEvent, 20, 20, 100))
+	now = now.Add(time.Second)
+	// 分块 1 重试，扣除已计入的字节
+	p1.ProgressChangedCallback(newProgressEvent(ProgressStartedEvent, 0, 0, 100))
+	if len(l.events) != 3 {
+		t.Fatalf("got %d events, want 3: %+v", len(l.events), l.events)
+	}
+	if e := l.events[1]; e.ConsumedBytes != 50 || e.Throughput != 50 || e.ETA != 5*time.Second {
+		t.Errorf("unexpected event: %+v", e)
+	}
+	if e := l.events[2]; e.ConsumedBytes != 20 || e.Elapsed != 2*time.Second {
+		t.Errorf("unexpected event: %+v", e)
+	}
+	now = now.Add(time.Second)
+	tracker.CompletePart(1, 100)
+	tracker.CompletePart(1, 100)
+	// 已完成分块的迟到事件被忽略
+	p1.ProgressChangedCallback(newProgressEvent(ProgressDataEvent, 10, 10, 100))
+	if p := tracker.Progress(); p.ConsumedBytes != 120 || p.CompletedParts != 1 || p.Throughput != 40 {
+		t.Errorf("unexpected progress: %+v", p)
+	}
+	now = now.Add(10 * time.Second)
+	tracker.CompletePart(2, 100)
+	tracker.CompletePart(3, 100)
+	tracker.Complete()
+	tracker.Fail(errors.New("ignored"))
+	l.check(t, 300, 3)
+	// 滑动窗口之外的样本不参与速率计算
+	if e := l.events[len(l.events)-1]; e.Throughput != 18 {
+		t.Errorf("unexpected throughput: %+v", e)
+	}
+}
+
+func TestObjectService_Upload_TransferProgress(t *testing.T) {
+	setup()
+	defer teardown()
+
+	filePath := "tmpfile" + time.Now().Format(time.RFC3339)
+	b := make([]byte, 1024*1024*3+10)
+	rand.Read(b)
+	if err := ioutil.WriteFile(filePath, b, 0644); err != nil {
+		t.Fatalf("create tmp file failed")
+	}
+	defer os.Remove(filePath)
+
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	mux.HandleFunc("/test.go.upload", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch {
+		case r.Method == http.MethodPut:
+			bs, _ := ioutil.ReadAll(r.Body)
+			part := r.Form.Get("partNumber")
+			mu.Lock()
+			attempts[part]++
+			n := attempts[part]
+			mu.Unlock()
+			// 每个分块第一次上传失败，触发重试
+			if n == 1 {
+				w.WriteHeader(http.StatusGatewayTimeout)
+				return
+			}
+			w.Header().Add("ETag", "etag"+part)
+			w.Header().Add("x-cos-hash-crc64ecma", strconv.FormatUint(crc64.Checksum(bs, crc64.MakeTable(crc64.ECMA)), 10))
+		case r.Form.Get("uploadId") == "":
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>uploadid</UploadId></InitiateMultipartUploadResult>`)
+		default:
+			fmt.Fprint(w, `<CompleteMultipartUploadResult><Key>test.go.upload</Key><ETag>etag</ETag></CompleteMultipartUploadResult>`)
+		}
+	})
+
+	l := &testTransferListener{}
+	opt := &MultiUploadOptions{
+		PartSize:        1,
+		ThreadPoolSize:  3,
+		DisableChecksum: true,
+		Progress:        &TransferProgressOptions{Listener: l, Interval: -1},
+	}
+	_, _, err := client.Object.Upload(context.Background(), "test.go.upload", filePath, opt)
+	if err != nil {
+		t.Fatalf("Object.Upload returned error: %v", err)
+	}
+	l.check(t, int64(len(b)), 4)
+}
+
+func TestObjectService_Upload_TransferProgressCompleteFailed(t *testing.T) {
+	setup()
+	defer teardown()
+
+	filePath := "tmpfile" + time.Now().Format(time.RFC3339)
+	b := make([]byte, 1024*1024*2+10)
+	rand.Read(b)
+	if err := ioutil.WriteFile(filePath, b, 0644); err != nil {
+		t.Fatalf("create tmp file failed")
+	}
+	defer os.Remove(filePath)
+
+	var completeFailed bool
+	mux.HandleFunc("/test.go.upload", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch {
+		case r.Method == http.MethodPut:
+			bs, _ := ioutil.ReadAll(r.Body)
+			w.Header().Add("ETag", "etag"+r.Form.Get("partNumber"))
+			w.Header().Add("x-cos-hash-crc64ecma", strconv.FormatUint(crc64.Checksum(bs, crc64.MakeTable(crc64.ECMA)), 10))
+		case r.Form.Get("uploadId") == "":
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>uploadid</UploadId></InitiateMultipartUploadResult>`)
+		case completeFailed:
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			// CRC64 与本地文件不一致
+			w.Header().Add("x-cos-hash-crc64ecma", "1")
+			fmt.Fprint(w, `<CompleteMultipartUploadResult><Key>test.go.upload</Key><ETag>etag</ETag></CompleteMultipartUploadResult>`)
+		}
+	})
+
+	// 合并分块失败或 CRC64 校验失败时，最后一个事件为 ProgressFailedEvent
+	for _, completeFailed = range []bool{true, false} {
+		l := &testTransferListener{}
+		opt := &MultiUploadOptions{
+			PartSize:       1,
+			ThreadPoolSize: 2,
+			Progress:       &TransferProgressOptions{Listener: l, Interval: -1},
+		}
+		_, _, err := client.Object.Upload(context.Background(), "test.go.upload", filePath, opt)
+		if err == nil {
+			t.Fatalf("Object.Upload expect error, completeFailed: %v", completeFailed)
+		}
+		l.mu.Lock()
+		for _, e := range l.events {
+			if e.EventType == ProgressCompletedEvent {
+				t.Errorf("unexpected completed event: %+v", e)
+			}
+		}
+		if last := l.events[len(l.events)-1]; last.EventType != ProgressFailedEvent || last.Err == nil {
+			t.Errorf("unexpected last event: %+v", last)
+		}
+		l.mu.Unlock()
+	}
+}
+
+func TestObjectService_Download_TransferProgress(t *testing.T) {
+	setup()
+	defer teardown()
+
+	b := make([]byte, 1024*1024*3)
+	rand.Read(b)
+	mux.HandleFunc("/test.go.download", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(b))
+	})
+
+	filePath := "tmpfile" + time.Now().Format(time.RFC3339)
+	defer os.Remove(filePath)
+	for _, partSize := range []int64{1, 10} {
+		l := &testTransferListener{}
+		opt := &MultiDownloadOptions{
+			PartSize:       partSize,
+			ThreadPoolSize: 2,
+			Progress:       &TransferProgressOptions{Listener: l, Interval: -1},
+		}
+		_, err := client.Object.Download(context.Background(), "test.go.download", filePath, opt)
+		if err != nil {
+			t.Fatalf("Object.Download returned error: %v", err)
+		}
+		totalParts := 3
+		if partSize == 10 {
+			totalParts = 1
+		}
+		l.check(t, int64(len(b)), totalParts)
+		data, _ := ioutil.ReadFile(filePath)
+		if !bytes.Equal(data, b) {
+			t.Errorf("Object.Download data mismatch")
+		}
+	}
+}
+
+func TestObjectService_MultiCopy_TransferProgress(t *testing.T) {
+	setup()
+	defer teardown()
+
+	totalBytes := 1024*1024*2 + 1
+	mux.HandleFunc("/test.src", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Length", strconv.Itoa(totalBytes))
+	})
+	mux.HandleFunc("/test.dst", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch {
+		case r.Method == http.MethodPut && r.Form.Get("partNumber") != "":
+			fmt.Fprint(w, `<CopyPartResult><ETag>etag</ETag></CopyPartResult>`)
+		case r.Method == http.MethodPut:
+			fmt.Fprint(w, `<CopyObjectResult><ETag>etag</ETag></CopyObjectResult>`)
+		case r.Form.Get("uploadId") == "":
+			fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>uploadid</UploadId></InitiateMultipartUploadResult>`)
+		default:
+			fmt.Fprint(w, `<CompleteMultipartUploadResult><ETag>etag</ETag></CompleteMultipartUploadResult>`)
+		}
+	})
+
+	source := client.BaseURL.BucketURL.Host + "/test.src"
+	for _, useMulti := range []bool{true, false} {
+		l := &testTransferListener{}
+		opt := &MultiCopyOptions{
+			PartSize:       1,
+			ThreadPoolSize: 2,
+			useMulti:       useMulti,
+			Progress:       &TransferProgressOptions{Listener: l},
+		}
+		_, _, err := client.Object.MultiCopy(context.Background(), "test.dst", source, opt)
+		if err != nil {
+			t.Fatalf("Object.MultiCopy returned error: %v", err)
+		}
+		totalParts := 3
+		if !useMulti {
+			totalParts = 1
+		}
+		l.check(t, int64(totalBytes), totalParts)
+	}
+}
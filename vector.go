@@ -166,6 +166,9 @@ func (s *VectorService) vectorNewRequest(ctx context.Context, uri, method string
 	if isRetry {
 		req.Header.Set("X-Cos-Sdk-Retry", "true")
 	}
+	if host := s.client.signingHost(baseURL); host != "" {
+		req.Host = host
+	}
 	if s.client.Host != "" {
 		req.Host = s.client.Host
 	}
@@ -178,12 +181,24 @@ func (s *VectorService) vectorNewRequest(ctx context.Context, uri, method string
 // vectorSend 向量服务专用的请求发送方法
 // 认证信息由 http.Client.Transport（如 AuthorizationTransport）自动注入
 func (s *VectorService) vectorSend(ctx context.Context, uri, method string, body interface{}, result interface{}, isRetry bool) (*Response, error) {
-	req, err := s.vectorNewRequest(ctx, uri, method, body, isRetry)
-	if err != nil {
-		return nil, err
-	}
+	rewind := bodyRewinder(body)
+	for nr := 0; ; nr++ {
+		sctx := s.client.withClockOffset(ctx)
+		if err := s.client.Conf.RateLimit.waitRequest(sctx, method); err != nil {
+			return nil, err
+		}
+		req, err := s.vectorNewRequest(sctx, uri, method, body, isRetry)
+		if err != nil {
+			return nil, err
+		}
 
-	return s.vectorDoAPI(ctx, req, result)
+		resp, err := s.vectorDoAPI(sctx, req, result)
+		// 本地时钟偏差导致签名失败时，校正后重新签名并重试一次
+		if nr == 0 && s.client.correctClockSkew(resp, err) && rewind() {
+			continue
+		}
+		return resp, err
+	}
 }
 
 // vectorCheckRetrieable 向量服务专用的重试判断
@@ -106,6 +106,18 @@ func TestNewVectorURL_EmptyRegion(t *testing.T) {
 	}
 }
 
+func TestNewVectorURL_AnyRegion(t *testing.T) {
+	// 不校验 region 的格式
+	u, err := NewVectorURL("ap_guangzhou", true)
+	if err != nil || u.Host != "vectors.ap_guangzhou.coslake.com" {
+		t.Errorf("NewVectorURL returned %v, error: %v", u, err)
+	}
+	u, err = NewVectorInternalURL("ap_guangzhou", true)
+	if err != nil || u.Host != "vectors.ap_guangzhou.internal.tencentcos.com" {
+		t.Errorf("NewVectorInternalURL returned %v, error: %v", u, err)
+	}
+}
+
 func TestNewVectorInternalURL(t *testing.T) {
 	u, err := NewVectorInternalURL("ap-guangzhou", true)
 	if err != nil {